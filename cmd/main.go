@@ -4,8 +4,11 @@ import (
 	"context"
 	"go.uber.org/zap"
 	"log"
+	"os"
+	"os/signal"
 	"player-tracker/internal/app"
 	"player-tracker/internal/config"
+	"syscall"
 )
 
 func main() {
@@ -20,7 +23,15 @@ func main() {
 	}
 	logger := unsugared.Sugar()
 
-	ctx := context.Background()
+	// The context is cancelled on the first signal, a second signal kills the process as usual
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// `player-tracker migrate` only applies migrations, so they can be run without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		app.Migrate(ctx, cfg, logger)
+		return
+	}
 
 	app.Run(ctx, cfg, logger)
 }
@@ -0,0 +1,262 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.21.12
+// source: playertracker/admin.proto
+
+package trackerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PurgeServerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Server:
+	//	*PurgeServerRequest_ProxyId
+	//	*PurgeServerRequest_GameServerId
+	Server isPurgeServerRequest_Server `protobuf_oneof:"server"`
+}
+
+func (x *PurgeServerRequest) Reset() {
+	*x = PurgeServerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_admin_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PurgeServerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeServerRequest) ProtoMessage() {}
+
+func (x *PurgeServerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_admin_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeServerRequest.ProtoReflect.Descriptor instead.
+func (*PurgeServerRequest) Descriptor() ([]byte, []int) {
+	return file_playertracker_admin_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *PurgeServerRequest) GetServer() isPurgeServerRequest_Server {
+	if m != nil {
+		return m.Server
+	}
+	return nil
+}
+
+func (x *PurgeServerRequest) GetProxyId() string {
+	if x, ok := x.GetServer().(*PurgeServerRequest_ProxyId); ok {
+		return x.ProxyId
+	}
+	return ""
+}
+
+func (x *PurgeServerRequest) GetGameServerId() string {
+	if x, ok := x.GetServer().(*PurgeServerRequest_GameServerId); ok {
+		return x.GameServerId
+	}
+	return ""
+}
+
+type isPurgeServerRequest_Server interface {
+	isPurgeServerRequest_Server()
+}
+
+type PurgeServerRequest_ProxyId struct {
+	// Every player on the proxy is disconnected.
+	ProxyId string `protobuf:"bytes,1,opt,name=proxy_id,json=proxyId,proto3,oneof"`
+}
+
+type PurgeServerRequest_GameServerId struct {
+	// Every player on the game server is left connected to their proxy without a game server.
+	GameServerId string `protobuf:"bytes,2,opt,name=game_server_id,json=gameServerId,proto3,oneof"`
+}
+
+func (*PurgeServerRequest_ProxyId) isPurgeServerRequest_Server() {}
+
+func (*PurgeServerRequest_GameServerId) isPurgeServerRequest_Server() {}
+
+type PurgeServerResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The IDs of the players that were purged.
+	PlayerIds []string `protobuf:"bytes,1,rep,name=player_ids,json=playerIds,proto3" json:"player_ids,omitempty"`
+}
+
+func (x *PurgeServerResponse) Reset() {
+	*x = PurgeServerResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_admin_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PurgeServerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeServerResponse) ProtoMessage() {}
+
+func (x *PurgeServerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_admin_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeServerResponse.ProtoReflect.Descriptor instead.
+func (*PurgeServerResponse) Descriptor() ([]byte, []int) {
+	return file_playertracker_admin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PurgeServerResponse) GetPlayerIds() []string {
+	if x != nil {
+		return x.PlayerIds
+	}
+	return nil
+}
+
+var File_playertracker_admin_proto protoreflect.FileDescriptor
+
+var file_playertracker_admin_proto_rawDesc = []byte{
+	0x0a, 0x19, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2f,
+	0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x15, 0x65, 0x6d, 0x6f,
+	0x72, 0x74, 0x61, 0x6c, 0x2e, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b,
+	0x65, 0x72, 0x22, 0x63, 0x0a, 0x12, 0x50, 0x75, 0x72, 0x67, 0x65, 0x53, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x78,
+	0x79, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x07, 0x70, 0x72,
+	0x6f, 0x78, 0x79, 0x49, 0x64, 0x12, 0x26, 0x0a, 0x0e, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52,
+	0x0c, 0x67, 0x61, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x64, 0x42, 0x08, 0x0a,
+	0x06, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x22, 0x34, 0x0a, 0x13, 0x50, 0x75, 0x72, 0x67, 0x65,
+	0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d,
+	0x0a, 0x0a, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x09, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x73, 0x32, 0x7a, 0x0a,
+	0x12, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x41, 0x64,
+	0x6d, 0x69, 0x6e, 0x12, 0x64, 0x0a, 0x0b, 0x50, 0x75, 0x72, 0x67, 0x65, 0x53, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x12, 0x29, 0x2e, 0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2e, 0x70, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x50, 0x75, 0x72, 0x67, 0x65,
+	0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2a, 0x2e,
+	0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2e, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72,
+	0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x50, 0x75, 0x72, 0x67, 0x65, 0x53, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x1e, 0x5a, 0x1c, 0x70, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x2d, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2f, 0x67, 0x65, 0x6e, 0x2f,
+	0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_playertracker_admin_proto_rawDescOnce sync.Once
+	file_playertracker_admin_proto_rawDescData = file_playertracker_admin_proto_rawDesc
+)
+
+func file_playertracker_admin_proto_rawDescGZIP() []byte {
+	file_playertracker_admin_proto_rawDescOnce.Do(func() {
+		file_playertracker_admin_proto_rawDescData = protoimpl.X.CompressGZIP(file_playertracker_admin_proto_rawDescData)
+	})
+	return file_playertracker_admin_proto_rawDescData
+}
+
+var file_playertracker_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_playertracker_admin_proto_goTypes = []interface{}{
+	(*PurgeServerRequest)(nil),  // 0: emortal.playertracker.PurgeServerRequest
+	(*PurgeServerResponse)(nil), // 1: emortal.playertracker.PurgeServerResponse
+}
+var file_playertracker_admin_proto_depIdxs = []int32{
+	0, // 0: emortal.playertracker.PlayerTrackerAdmin.PurgeServer:input_type -> emortal.playertracker.PurgeServerRequest
+	1, // 1: emortal.playertracker.PlayerTrackerAdmin.PurgeServer:output_type -> emortal.playertracker.PurgeServerResponse
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_playertracker_admin_proto_init() }
+func file_playertracker_admin_proto_init() {
+	if File_playertracker_admin_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_playertracker_admin_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PurgeServerRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_playertracker_admin_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PurgeServerResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_playertracker_admin_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*PurgeServerRequest_ProxyId)(nil),
+		(*PurgeServerRequest_GameServerId)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_playertracker_admin_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_playertracker_admin_proto_goTypes,
+		DependencyIndexes: file_playertracker_admin_proto_depIdxs,
+		MessageInfos:      file_playertracker_admin_proto_msgTypes,
+	}.Build()
+	File_playertracker_admin_proto = out.File
+	file_playertracker_admin_proto_rawDesc = nil
+	file_playertracker_admin_proto_goTypes = nil
+	file_playertracker_admin_proto_depIdxs = nil
+}
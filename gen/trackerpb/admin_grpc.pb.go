@@ -0,0 +1,111 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.12
+// source: playertracker/admin.proto
+
+package trackerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// PlayerTrackerAdminClient is the client API for PlayerTrackerAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PlayerTrackerAdminClient interface {
+	// PurgeServer removes the players of a proxy or game server that has gone without the tracker being told,
+	// the same as if it had received a ProxyShutdownMessage or ServerShutdownMessage for it.
+	// Players who have connected or switched server since the request was received are left as they are.
+	PurgeServer(ctx context.Context, in *PurgeServerRequest, opts ...grpc.CallOption) (*PurgeServerResponse, error)
+}
+
+type playerTrackerAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPlayerTrackerAdminClient(cc grpc.ClientConnInterface) PlayerTrackerAdminClient {
+	return &playerTrackerAdminClient{cc}
+}
+
+func (c *playerTrackerAdminClient) PurgeServer(ctx context.Context, in *PurgeServerRequest, opts ...grpc.CallOption) (*PurgeServerResponse, error) {
+	out := new(PurgeServerResponse)
+	err := c.cc.Invoke(ctx, "/emortal.playertracker.PlayerTrackerAdmin/PurgeServer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PlayerTrackerAdminServer is the server API for PlayerTrackerAdmin service.
+// All implementations must embed UnimplementedPlayerTrackerAdminServer
+// for forward compatibility
+type PlayerTrackerAdminServer interface {
+	// PurgeServer removes the players of a proxy or game server that has gone without the tracker being told,
+	// the same as if it had received a ProxyShutdownMessage or ServerShutdownMessage for it.
+	// Players who have connected or switched server since the request was received are left as they are.
+	PurgeServer(context.Context, *PurgeServerRequest) (*PurgeServerResponse, error)
+	mustEmbedUnimplementedPlayerTrackerAdminServer()
+}
+
+// UnimplementedPlayerTrackerAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedPlayerTrackerAdminServer struct {
+}
+
+func (UnimplementedPlayerTrackerAdminServer) PurgeServer(context.Context, *PurgeServerRequest) (*PurgeServerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeServer not implemented")
+}
+func (UnimplementedPlayerTrackerAdminServer) mustEmbedUnimplementedPlayerTrackerAdminServer() {}
+
+// UnsafePlayerTrackerAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PlayerTrackerAdminServer will
+// result in compilation errors.
+type UnsafePlayerTrackerAdminServer interface {
+	mustEmbedUnimplementedPlayerTrackerAdminServer()
+}
+
+func RegisterPlayerTrackerAdminServer(s grpc.ServiceRegistrar, srv PlayerTrackerAdminServer) {
+	s.RegisterService(&PlayerTrackerAdmin_ServiceDesc, srv)
+}
+
+func _PlayerTrackerAdmin_PurgeServer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeServerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerTrackerAdminServer).PurgeServer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/emortal.playertracker.PlayerTrackerAdmin/PurgeServer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerTrackerAdminServer).PurgeServer(ctx, req.(*PurgeServerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PlayerTrackerAdmin_ServiceDesc is the grpc.ServiceDesc for PlayerTrackerAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PlayerTrackerAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "emortal.playertracker.PlayerTrackerAdmin",
+	HandlerType: (*PlayerTrackerAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PurgeServer",
+			Handler:    _PlayerTrackerAdmin_PurgeServer_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "playertracker/admin.proto",
+}
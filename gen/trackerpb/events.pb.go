@@ -0,0 +1,359 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.21.12
+// source: playertracker/events.proto
+
+package trackerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PlayerLocationChangedMessage_Reason int32
+
+const (
+	PlayerLocationChangedMessage_CONNECTED       PlayerLocationChangedMessage_Reason = 0
+	PlayerLocationChangedMessage_SWITCHED_SERVER PlayerLocationChangedMessage_Reason = 1
+	PlayerLocationChangedMessage_DISCONNECTED    PlayerLocationChangedMessage_Reason = 2
+)
+
+// Enum value maps for PlayerLocationChangedMessage_Reason.
+var (
+	PlayerLocationChangedMessage_Reason_name = map[int32]string{
+		0: "CONNECTED",
+		1: "SWITCHED_SERVER",
+		2: "DISCONNECTED",
+	}
+	PlayerLocationChangedMessage_Reason_value = map[string]int32{
+		"CONNECTED":       0,
+		"SWITCHED_SERVER": 1,
+		"DISCONNECTED":    2,
+	}
+)
+
+func (x PlayerLocationChangedMessage_Reason) Enum() *PlayerLocationChangedMessage_Reason {
+	p := new(PlayerLocationChangedMessage_Reason)
+	*p = x
+	return p
+}
+
+func (x PlayerLocationChangedMessage_Reason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PlayerLocationChangedMessage_Reason) Descriptor() protoreflect.EnumDescriptor {
+	return file_playertracker_events_proto_enumTypes[0].Descriptor()
+}
+
+func (PlayerLocationChangedMessage_Reason) Type() protoreflect.EnumType {
+	return &file_playertracker_events_proto_enumTypes[0]
+}
+
+func (x PlayerLocationChangedMessage_Reason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PlayerLocationChangedMessage_Reason.Descriptor instead.
+func (PlayerLocationChangedMessage_Reason) EnumDescriptor() ([]byte, []int) {
+	return file_playertracker_events_proto_rawDescGZIP(), []int{0, 0}
+}
+
+// PlayerLocationChangedMessage is published after the tracker has applied a change to a player's location.
+// It is routed by its full name, and its AMQP message ID is the ID of the message that caused the change if it had one.
+// Players purged because their proxy or game server went each have their own message ID instead, see PlayerTrackerAdmin.
+//
+// Delivery is at least once, so consumers should ignore message IDs they have already seen.
+type PlayerLocationChangedMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PlayerId string                              `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Username string                              `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Reason   PlayerLocationChangedMessage_Reason `protobuf:"varint,3,opt,name=reason,proto3,enum=emortal.playertracker.PlayerLocationChangedMessage_Reason" json:"reason,omitempty"`
+	// Where the player was before the change, not set if they were offline or replayed is true.
+	Previous *PlayerLocation `protobuf:"bytes,4,opt,name=previous,proto3" json:"previous,omitempty"`
+	// Where the player is after the change, not set if they disconnected.
+	Current *PlayerLocation        `protobuf:"bytes,5,opt,name=current,proto3" json:"current,omitempty"`
+	At      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=at,proto3" json:"at,omitempty"`
+	// Whether the change had already been applied when the message causing it was redelivered, e.g. after a crash.
+	// The previous location is no longer known, so it is not set.
+	Replayed bool `protobuf:"varint,7,opt,name=replayed,proto3" json:"replayed,omitempty"`
+}
+
+func (x *PlayerLocationChangedMessage) Reset() {
+	*x = PlayerLocationChangedMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_events_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlayerLocationChangedMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerLocationChangedMessage) ProtoMessage() {}
+
+func (x *PlayerLocationChangedMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_events_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerLocationChangedMessage.ProtoReflect.Descriptor instead.
+func (*PlayerLocationChangedMessage) Descriptor() ([]byte, []int) {
+	return file_playertracker_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PlayerLocationChangedMessage) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *PlayerLocationChangedMessage) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *PlayerLocationChangedMessage) GetReason() PlayerLocationChangedMessage_Reason {
+	if x != nil {
+		return x.Reason
+	}
+	return PlayerLocationChangedMessage_CONNECTED
+}
+
+func (x *PlayerLocationChangedMessage) GetPrevious() *PlayerLocation {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+func (x *PlayerLocationChangedMessage) GetCurrent() *PlayerLocation {
+	if x != nil {
+		return x.Current
+	}
+	return nil
+}
+
+func (x *PlayerLocationChangedMessage) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+func (x *PlayerLocationChangedMessage) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+type PlayerLocation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ServerId string `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	ProxyId  string `protobuf:"bytes,2,opt,name=proxy_id,json=proxyId,proto3" json:"proxy_id,omitempty"`
+}
+
+func (x *PlayerLocation) Reset() {
+	*x = PlayerLocation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_events_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlayerLocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerLocation) ProtoMessage() {}
+
+func (x *PlayerLocation) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_events_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerLocation.ProtoReflect.Descriptor instead.
+func (*PlayerLocation) Descriptor() ([]byte, []int) {
+	return file_playertracker_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PlayerLocation) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *PlayerLocation) GetProxyId() string {
+	if x != nil {
+		return x.ProxyId
+	}
+	return ""
+}
+
+var File_playertracker_events_proto protoreflect.FileDescriptor
+
+var file_playertracker_events_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2f,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x15, 0x65, 0x6d,
+	0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2e, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63,
+	0x6b, 0x65, 0x72, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0xb7, 0x03, 0x0a, 0x1c, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x4c,
+	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x52,
+	0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x3a,
+	0x2e, 0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2e, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74,
+	0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x4c, 0x6f, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x2e, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x12, 0x41, 0x0a, 0x08, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2e, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x50, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x70, 0x72, 0x65,
+	0x76, 0x69, 0x6f, 0x75, 0x73, 0x12, 0x3f, 0x0a, 0x07, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c,
+	0x2e, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x50,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x63,
+	0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x2a, 0x0a, 0x02, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02,
+	0x61, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x22, 0x3e,
+	0x0a, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x4f, 0x4e, 0x4e,
+	0x45, 0x43, 0x54, 0x45, 0x44, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x57, 0x49, 0x54, 0x43,
+	0x48, 0x45, 0x44, 0x5f, 0x53, 0x45, 0x52, 0x56, 0x45, 0x52, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c,
+	0x44, 0x49, 0x53, 0x43, 0x4f, 0x4e, 0x4e, 0x45, 0x43, 0x54, 0x45, 0x44, 0x10, 0x02, 0x22, 0x48,
+	0x0a, 0x0e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x64, 0x12, 0x19, 0x0a,
+	0x08, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x49, 0x64, 0x42, 0x1e, 0x5a, 0x1c, 0x70, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x2d, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x74,
+	0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_playertracker_events_proto_rawDescOnce sync.Once
+	file_playertracker_events_proto_rawDescData = file_playertracker_events_proto_rawDesc
+)
+
+func file_playertracker_events_proto_rawDescGZIP() []byte {
+	file_playertracker_events_proto_rawDescOnce.Do(func() {
+		file_playertracker_events_proto_rawDescData = protoimpl.X.CompressGZIP(file_playertracker_events_proto_rawDescData)
+	})
+	return file_playertracker_events_proto_rawDescData
+}
+
+var file_playertracker_events_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_playertracker_events_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_playertracker_events_proto_goTypes = []interface{}{
+	(PlayerLocationChangedMessage_Reason)(0), // 0: emortal.playertracker.PlayerLocationChangedMessage.Reason
+	(*PlayerLocationChangedMessage)(nil),     // 1: emortal.playertracker.PlayerLocationChangedMessage
+	(*PlayerLocation)(nil),                   // 2: emortal.playertracker.PlayerLocation
+	(*timestamppb.Timestamp)(nil),            // 3: google.protobuf.Timestamp
+}
+var file_playertracker_events_proto_depIdxs = []int32{
+	0, // 0: emortal.playertracker.PlayerLocationChangedMessage.reason:type_name -> emortal.playertracker.PlayerLocationChangedMessage.Reason
+	2, // 1: emortal.playertracker.PlayerLocationChangedMessage.previous:type_name -> emortal.playertracker.PlayerLocation
+	2, // 2: emortal.playertracker.PlayerLocationChangedMessage.current:type_name -> emortal.playertracker.PlayerLocation
+	3, // 3: emortal.playertracker.PlayerLocationChangedMessage.at:type_name -> google.protobuf.Timestamp
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_playertracker_events_proto_init() }
+func file_playertracker_events_proto_init() {
+	if File_playertracker_events_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_playertracker_events_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PlayerLocationChangedMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_playertracker_events_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PlayerLocation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_playertracker_events_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_playertracker_events_proto_goTypes,
+		DependencyIndexes: file_playertracker_events_proto_depIdxs,
+		EnumInfos:         file_playertracker_events_proto_enumTypes,
+		MessageInfos:      file_playertracker_events_proto_msgTypes,
+	}.Build()
+	File_playertracker_events_proto = out.File
+	file_playertracker_events_proto_rawDesc = nil
+	file_playertracker_events_proto_goTypes = nil
+	file_playertracker_events_proto_depIdxs = nil
+}
@@ -0,0 +1,145 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.12
+// source: playertracker/history.proto
+
+package trackerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// PlayerHistoryClient is the client API for PlayerHistory service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PlayerHistoryClient interface {
+	// GetPlayerSessions returns the player's most recent sessions, newest first.
+	GetPlayerSessions(ctx context.Context, in *GetPlayerSessionsRequest, opts ...grpc.CallOption) (*GetPlayerSessionsResponse, error)
+	// GetPlayerLastSeen returns when and where the player was last online.
+	GetPlayerLastSeen(ctx context.Context, in *GetPlayerLastSeenRequest, opts ...grpc.CallOption) (*GetPlayerLastSeenResponse, error)
+}
+
+type playerHistoryClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPlayerHistoryClient(cc grpc.ClientConnInterface) PlayerHistoryClient {
+	return &playerHistoryClient{cc}
+}
+
+func (c *playerHistoryClient) GetPlayerSessions(ctx context.Context, in *GetPlayerSessionsRequest, opts ...grpc.CallOption) (*GetPlayerSessionsResponse, error) {
+	out := new(GetPlayerSessionsResponse)
+	err := c.cc.Invoke(ctx, "/emortal.playertracker.PlayerHistory/GetPlayerSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *playerHistoryClient) GetPlayerLastSeen(ctx context.Context, in *GetPlayerLastSeenRequest, opts ...grpc.CallOption) (*GetPlayerLastSeenResponse, error) {
+	out := new(GetPlayerLastSeenResponse)
+	err := c.cc.Invoke(ctx, "/emortal.playertracker.PlayerHistory/GetPlayerLastSeen", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PlayerHistoryServer is the server API for PlayerHistory service.
+// All implementations must embed UnimplementedPlayerHistoryServer
+// for forward compatibility
+type PlayerHistoryServer interface {
+	// GetPlayerSessions returns the player's most recent sessions, newest first.
+	GetPlayerSessions(context.Context, *GetPlayerSessionsRequest) (*GetPlayerSessionsResponse, error)
+	// GetPlayerLastSeen returns when and where the player was last online.
+	GetPlayerLastSeen(context.Context, *GetPlayerLastSeenRequest) (*GetPlayerLastSeenResponse, error)
+	mustEmbedUnimplementedPlayerHistoryServer()
+}
+
+// UnimplementedPlayerHistoryServer must be embedded to have forward compatible implementations.
+type UnimplementedPlayerHistoryServer struct {
+}
+
+func (UnimplementedPlayerHistoryServer) GetPlayerSessions(context.Context, *GetPlayerSessionsRequest) (*GetPlayerSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlayerSessions not implemented")
+}
+func (UnimplementedPlayerHistoryServer) GetPlayerLastSeen(context.Context, *GetPlayerLastSeenRequest) (*GetPlayerLastSeenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlayerLastSeen not implemented")
+}
+func (UnimplementedPlayerHistoryServer) mustEmbedUnimplementedPlayerHistoryServer() {}
+
+// UnsafePlayerHistoryServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PlayerHistoryServer will
+// result in compilation errors.
+type UnsafePlayerHistoryServer interface {
+	mustEmbedUnimplementedPlayerHistoryServer()
+}
+
+func RegisterPlayerHistoryServer(s grpc.ServiceRegistrar, srv PlayerHistoryServer) {
+	s.RegisterService(&PlayerHistory_ServiceDesc, srv)
+}
+
+func _PlayerHistory_GetPlayerSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPlayerSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerHistoryServer).GetPlayerSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/emortal.playertracker.PlayerHistory/GetPlayerSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerHistoryServer).GetPlayerSessions(ctx, req.(*GetPlayerSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlayerHistory_GetPlayerLastSeen_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPlayerLastSeenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerHistoryServer).GetPlayerLastSeen(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/emortal.playertracker.PlayerHistory/GetPlayerLastSeen",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerHistoryServer).GetPlayerLastSeen(ctx, req.(*GetPlayerLastSeenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PlayerHistory_ServiceDesc is the grpc.ServiceDesc for PlayerHistory service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PlayerHistory_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "emortal.playertracker.PlayerHistory",
+	HandlerType: (*PlayerHistoryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPlayerSessions",
+			Handler:    _PlayerHistory_GetPlayerSessions_Handler,
+		},
+		{
+			MethodName: "GetPlayerLastSeen",
+			Handler:    _PlayerHistory_GetPlayerLastSeen_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "playertracker/history.proto",
+}
@@ -0,0 +1,444 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.21.12
+// source: playertracker/messages.proto
+
+package trackerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ProxyShutdownMessage is sent when a proxy shuts down, or by whatever notices it has gone if it crashed.
+// Every player still connected to it is disconnected, as the proxy won't send their disconnects.
+type ProxyShutdownMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ProxyId string `protobuf:"bytes,1,opt,name=proxy_id,json=proxyId,proto3" json:"proxy_id,omitempty"`
+}
+
+func (x *ProxyShutdownMessage) Reset() {
+	*x = ProxyShutdownMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_messages_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProxyShutdownMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProxyShutdownMessage) ProtoMessage() {}
+
+func (x *ProxyShutdownMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_messages_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProxyShutdownMessage.ProtoReflect.Descriptor instead.
+func (*ProxyShutdownMessage) Descriptor() ([]byte, []int) {
+	return file_playertracker_messages_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ProxyShutdownMessage) GetProxyId() string {
+	if x != nil {
+		return x.ProxyId
+	}
+	return ""
+}
+
+// ServerShutdownMessage is sent when a game server shuts down, or by whatever notices it has gone if it crashed.
+// Every player still on it is left connected to their proxy without a game server, until the proxy sends them
+// somewhere else.
+type ServerShutdownMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ServerId string `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+}
+
+func (x *ServerShutdownMessage) Reset() {
+	*x = ServerShutdownMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_messages_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerShutdownMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerShutdownMessage) ProtoMessage() {}
+
+func (x *ServerShutdownMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_messages_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerShutdownMessage.ProtoReflect.Descriptor instead.
+func (*ServerShutdownMessage) Descriptor() ([]byte, []int) {
+	return file_playertracker_messages_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ServerShutdownMessage) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+// ProxyHeartbeatMessage is sent periodically by each proxy to renew its lease.
+// If a proxy stops sending them for longer than the tracker's lease, it is treated as having shut down without
+// sending a ProxyShutdownMessage. Proxies that have never sent one don't have a lease, so never expire.
+type ProxyHeartbeatMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ProxyId string `protobuf:"bytes,1,opt,name=proxy_id,json=proxyId,proto3" json:"proxy_id,omitempty"`
+}
+
+func (x *ProxyHeartbeatMessage) Reset() {
+	*x = ProxyHeartbeatMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_messages_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProxyHeartbeatMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProxyHeartbeatMessage) ProtoMessage() {}
+
+func (x *ProxyHeartbeatMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_messages_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProxyHeartbeatMessage.ProtoReflect.Descriptor instead.
+func (*ProxyHeartbeatMessage) Descriptor() ([]byte, []int) {
+	return file_playertracker_messages_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProxyHeartbeatMessage) GetProxyId() string {
+	if x != nil {
+		return x.ProxyId
+	}
+	return ""
+}
+
+// ProxySnapshotMessage is sent periodically by each proxy with every player connected to it, so the tracker can
+// correct players whose events were lost. Players connected to the proxy that the tracker doesn't have are connected,
+// players the tracker has on the proxy that aren't in the snapshot are disconnected, and players on the wrong game
+// server are moved. Players with an event after the snapshot was taken are left as they are.
+type ProxySnapshotMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ProxyId string            `protobuf:"bytes,1,opt,name=proxy_id,json=proxyId,proto3" json:"proxy_id,omitempty"`
+	Players []*SnapshotPlayer `protobuf:"bytes,2,rep,name=players,proto3" json:"players,omitempty"`
+}
+
+func (x *ProxySnapshotMessage) Reset() {
+	*x = ProxySnapshotMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_messages_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProxySnapshotMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProxySnapshotMessage) ProtoMessage() {}
+
+func (x *ProxySnapshotMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_messages_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProxySnapshotMessage.ProtoReflect.Descriptor instead.
+func (*ProxySnapshotMessage) Descriptor() ([]byte, []int) {
+	return file_playertracker_messages_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProxySnapshotMessage) GetProxyId() string {
+	if x != nil {
+		return x.ProxyId
+	}
+	return ""
+}
+
+func (x *ProxySnapshotMessage) GetPlayers() []*SnapshotPlayer {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+type SnapshotPlayer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PlayerId string `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Username string `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	// The game server the player is on, empty if they are between servers, in which case it isn't corrected
+	ServerId string `protobuf:"bytes,3,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+}
+
+func (x *SnapshotPlayer) Reset() {
+	*x = SnapshotPlayer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_messages_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SnapshotPlayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotPlayer) ProtoMessage() {}
+
+func (x *SnapshotPlayer) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_messages_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotPlayer.ProtoReflect.Descriptor instead.
+func (*SnapshotPlayer) Descriptor() ([]byte, []int) {
+	return file_playertracker_messages_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SnapshotPlayer) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *SnapshotPlayer) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *SnapshotPlayer) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+var File_playertracker_messages_proto protoreflect.FileDescriptor
+
+var file_playertracker_messages_proto_rawDesc = []byte{
+	0x0a, 0x1c, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2f,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x15,
+	0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2e, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72,
+	0x61, 0x63, 0x6b, 0x65, 0x72, 0x22, 0x31, 0x0a, 0x14, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x53, 0x68,
+	0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x19, 0x0a,
+	0x08, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x49, 0x64, 0x22, 0x34, 0x0a, 0x15, 0x53, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x64, 0x22, 0x32,
+	0x0a, 0x15, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x48, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x78, 0x79,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x78, 0x79,
+	0x49, 0x64, 0x22, 0x72, 0x0a, 0x14, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x72,
+	0x6f, 0x78, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x72,
+	0x6f, 0x78, 0x79, 0x49, 0x64, 0x12, 0x3f, 0x0a, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c,
+	0x2e, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x53,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x52, 0x07, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x22, 0x66, 0x0a, 0x0e, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
+	0x6f, 0x74, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x64, 0x42, 0x1e,
+	0x5a, 0x1c, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x2d, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72,
+	0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_playertracker_messages_proto_rawDescOnce sync.Once
+	file_playertracker_messages_proto_rawDescData = file_playertracker_messages_proto_rawDesc
+)
+
+func file_playertracker_messages_proto_rawDescGZIP() []byte {
+	file_playertracker_messages_proto_rawDescOnce.Do(func() {
+		file_playertracker_messages_proto_rawDescData = protoimpl.X.CompressGZIP(file_playertracker_messages_proto_rawDescData)
+	})
+	return file_playertracker_messages_proto_rawDescData
+}
+
+var file_playertracker_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_playertracker_messages_proto_goTypes = []interface{}{
+	(*ProxyShutdownMessage)(nil),  // 0: emortal.playertracker.ProxyShutdownMessage
+	(*ServerShutdownMessage)(nil), // 1: emortal.playertracker.ServerShutdownMessage
+	(*ProxyHeartbeatMessage)(nil), // 2: emortal.playertracker.ProxyHeartbeatMessage
+	(*ProxySnapshotMessage)(nil),  // 3: emortal.playertracker.ProxySnapshotMessage
+	(*SnapshotPlayer)(nil),        // 4: emortal.playertracker.SnapshotPlayer
+}
+var file_playertracker_messages_proto_depIdxs = []int32{
+	4, // 0: emortal.playertracker.ProxySnapshotMessage.players:type_name -> emortal.playertracker.SnapshotPlayer
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_playertracker_messages_proto_init() }
+func file_playertracker_messages_proto_init() {
+	if File_playertracker_messages_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_playertracker_messages_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProxyShutdownMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_playertracker_messages_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerShutdownMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_playertracker_messages_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProxyHeartbeatMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_playertracker_messages_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProxySnapshotMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_playertracker_messages_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SnapshotPlayer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_playertracker_messages_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_playertracker_messages_proto_goTypes,
+		DependencyIndexes: file_playertracker_messages_proto_depIdxs,
+		MessageInfos:      file_playertracker_messages_proto_msgTypes,
+	}.Build()
+	File_playertracker_messages_proto = out.File
+	file_playertracker_messages_proto_rawDesc = nil
+	file_playertracker_messages_proto_goTypes = nil
+	file_playertracker_messages_proto_depIdxs = nil
+}
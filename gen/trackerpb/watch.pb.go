@@ -0,0 +1,535 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.21.12
+// source: playertracker/watch.proto
+
+package trackerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PlayerUpdate_UpdateType int32
+
+const (
+	PlayerUpdate_SNAPSHOT        PlayerUpdate_UpdateType = 0
+	PlayerUpdate_CONNECTED       PlayerUpdate_UpdateType = 1
+	PlayerUpdate_SWITCHED_SERVER PlayerUpdate_UpdateType = 2
+	PlayerUpdate_DISCONNECTED    PlayerUpdate_UpdateType = 3
+)
+
+// Enum value maps for PlayerUpdate_UpdateType.
+var (
+	PlayerUpdate_UpdateType_name = map[int32]string{
+		0: "SNAPSHOT",
+		1: "CONNECTED",
+		2: "SWITCHED_SERVER",
+		3: "DISCONNECTED",
+	}
+	PlayerUpdate_UpdateType_value = map[string]int32{
+		"SNAPSHOT":        0,
+		"CONNECTED":       1,
+		"SWITCHED_SERVER": 2,
+		"DISCONNECTED":    3,
+	}
+)
+
+func (x PlayerUpdate_UpdateType) Enum() *PlayerUpdate_UpdateType {
+	p := new(PlayerUpdate_UpdateType)
+	*p = x
+	return p
+}
+
+func (x PlayerUpdate_UpdateType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PlayerUpdate_UpdateType) Descriptor() protoreflect.EnumDescriptor {
+	return file_playertracker_watch_proto_enumTypes[0].Descriptor()
+}
+
+func (PlayerUpdate_UpdateType) Type() protoreflect.EnumType {
+	return &file_playertracker_watch_proto_enumTypes[0]
+}
+
+func (x PlayerUpdate_UpdateType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PlayerUpdate_UpdateType.Descriptor instead.
+func (PlayerUpdate_UpdateType) EnumDescriptor() ([]byte, []int) {
+	return file_playertracker_watch_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type WatchPlayerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The players to watch, at most 100.
+	PlayerIds []string `protobuf:"bytes,1,rep,name=player_ids,json=playerIds,proto3" json:"player_ids,omitempty"`
+}
+
+func (x *WatchPlayerRequest) Reset() {
+	*x = WatchPlayerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_watch_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchPlayerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchPlayerRequest) ProtoMessage() {}
+
+func (x *WatchPlayerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_watch_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchPlayerRequest.ProtoReflect.Descriptor instead.
+func (*WatchPlayerRequest) Descriptor() ([]byte, []int) {
+	return file_playertracker_watch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WatchPlayerRequest) GetPlayerIds() []string {
+	if x != nil {
+		return x.PlayerIds
+	}
+	return nil
+}
+
+type PlayerUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PlayerId string                  `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Type     PlayerUpdate_UpdateType `protobuf:"varint,2,opt,name=type,proto3,enum=emortal.playertracker.PlayerUpdate_UpdateType" json:"type,omitempty"`
+	// Whether the player is online after the update. If false, the location fields are empty.
+	Online   bool   `protobuf:"varint,3,opt,name=online,proto3" json:"online,omitempty"`
+	Username string `protobuf:"bytes,4,opt,name=username,proto3" json:"username,omitempty"`
+	ServerId string `protobuf:"bytes,5,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	ProxyId  string `protobuf:"bytes,6,opt,name=proxy_id,json=proxyId,proto3" json:"proxy_id,omitempty"`
+	// When the update happened. Not set for snapshots.
+	At *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=at,proto3,oneof" json:"at,omitempty"`
+}
+
+func (x *PlayerUpdate) Reset() {
+	*x = PlayerUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_watch_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlayerUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerUpdate) ProtoMessage() {}
+
+func (x *PlayerUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_watch_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerUpdate.ProtoReflect.Descriptor instead.
+func (*PlayerUpdate) Descriptor() ([]byte, []int) {
+	return file_playertracker_watch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PlayerUpdate) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *PlayerUpdate) GetType() PlayerUpdate_UpdateType {
+	if x != nil {
+		return x.Type
+	}
+	return PlayerUpdate_SNAPSHOT
+}
+
+func (x *PlayerUpdate) GetOnline() bool {
+	if x != nil {
+		return x.Online
+	}
+	return false
+}
+
+func (x *PlayerUpdate) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *PlayerUpdate) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *PlayerUpdate) GetProxyId() string {
+	if x != nil {
+		return x.ProxyId
+	}
+	return ""
+}
+
+func (x *PlayerUpdate) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+type WatchPlayerCountsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Game server or proxy IDs, counted the same as GetServerPlayerCount. At most 100.
+	ServerIds []string `protobuf:"bytes,1,rep,name=server_ids,json=serverIds,proto3" json:"server_ids,omitempty"`
+	// emortal.model.ServerType values, counted the same as GetServerTypesPlayerCount. At most 100.
+	ServerTypes []int32 `protobuf:"varint,2,rep,packed,name=server_types,json=serverTypes,proto3" json:"server_types,omitempty"`
+}
+
+func (x *WatchPlayerCountsRequest) Reset() {
+	*x = WatchPlayerCountsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_watch_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchPlayerCountsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchPlayerCountsRequest) ProtoMessage() {}
+
+func (x *WatchPlayerCountsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_watch_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchPlayerCountsRequest.ProtoReflect.Descriptor instead.
+func (*WatchPlayerCountsRequest) Descriptor() ([]byte, []int) {
+	return file_playertracker_watch_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WatchPlayerCountsRequest) GetServerIds() []string {
+	if x != nil {
+		return x.ServerIds
+	}
+	return nil
+}
+
+func (x *WatchPlayerCountsRequest) GetServerTypes() []int32 {
+	if x != nil {
+		return x.ServerTypes
+	}
+	return nil
+}
+
+type PlayerCountsUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Keyed by server ID
+	ServerPlayerCounts map[string]uint32 `protobuf:"bytes,1,rep,name=server_player_counts,json=serverPlayerCounts,proto3" json:"server_player_counts,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	// Keyed by emortal.model.ServerType value
+	ServerTypePlayerCounts map[int32]uint32 `protobuf:"bytes,2,rep,name=server_type_player_counts,json=serverTypePlayerCounts,proto3" json:"server_type_player_counts,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *PlayerCountsUpdate) Reset() {
+	*x = PlayerCountsUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_playertracker_watch_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlayerCountsUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerCountsUpdate) ProtoMessage() {}
+
+func (x *PlayerCountsUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_playertracker_watch_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerCountsUpdate.ProtoReflect.Descriptor instead.
+func (*PlayerCountsUpdate) Descriptor() ([]byte, []int) {
+	return file_playertracker_watch_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PlayerCountsUpdate) GetServerPlayerCounts() map[string]uint32 {
+	if x != nil {
+		return x.ServerPlayerCounts
+	}
+	return nil
+}
+
+func (x *PlayerCountsUpdate) GetServerTypePlayerCounts() map[int32]uint32 {
+	if x != nil {
+		return x.ServerTypePlayerCounts
+	}
+	return nil
+}
+
+var File_playertracker_watch_proto protoreflect.FileDescriptor
+
+var file_playertracker_watch_proto_rawDesc = []byte{
+	0x0a, 0x19, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2f,
+	0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x15, 0x65, 0x6d, 0x6f,
+	0x72, 0x74, 0x61, 0x6c, 0x2e, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b,
+	0x65, 0x72, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0x33, 0x0a, 0x12, 0x57, 0x61, 0x74, 0x63, 0x68, 0x50, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x73, 0x22, 0xe5, 0x02, 0x0a, 0x0c, 0x50, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x49, 0x64, 0x12, 0x42, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x2e, 0x2e, 0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2e, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x50, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x6e,
+	0x6c, 0x69, 0x6e, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6f, 0x6e, 0x6c, 0x69,
+	0x6e, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1b,
+	0x0a, 0x09, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x70,
+	0x72, 0x6f, 0x78, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70,
+	0x72, 0x6f, 0x78, 0x79, 0x49, 0x64, 0x12, 0x2f, 0x0a, 0x02, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x48, 0x00,
+	0x52, 0x02, 0x61, 0x74, 0x88, 0x01, 0x01, 0x22, 0x50, 0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f,
+	0x54, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x4f, 0x4e, 0x4e, 0x45, 0x43, 0x54, 0x45, 0x44,
+	0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x57, 0x49, 0x54, 0x43, 0x48, 0x45, 0x44, 0x5f, 0x53,
+	0x45, 0x52, 0x56, 0x45, 0x52, 0x10, 0x02, 0x12, 0x10, 0x0a, 0x0c, 0x44, 0x49, 0x53, 0x43, 0x4f,
+	0x4e, 0x4e, 0x45, 0x43, 0x54, 0x45, 0x44, 0x10, 0x03, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x61, 0x74,
+	0x22, 0x5c, 0x0a, 0x18, 0x57, 0x61, 0x74, 0x63, 0x68, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x09, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x64, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x05, 0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x73, 0x22, 0x9e,
+	0x03, 0x0a, 0x12, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x55,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x73, 0x0a, 0x14, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f,
+	0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x41, 0x2e, 0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2e, 0x70, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x50, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x53,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x12, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x50, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x80, 0x01, 0x0a, 0x19, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x45,
+	0x2e, 0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2e, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74,
+	0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x54, 0x79, 0x70, 0x65, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x16, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54, 0x79, 0x70,
+	0x65, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x1a, 0x45, 0x0a,
+	0x17, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x1a, 0x49, 0x0a, 0x1b, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54, 0x79,
+	0x70, 0x65, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x32,
+	0xe1, 0x01, 0x0a, 0x0b, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x57, 0x61, 0x74, 0x63, 0x68, 0x12,
+	0x5f, 0x0a, 0x0b, 0x57, 0x61, 0x74, 0x63, 0x68, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x12, 0x29,
+	0x2e, 0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2e, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74,
+	0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x50, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x65, 0x6d, 0x6f, 0x72,
+	0x74, 0x61, 0x6c, 0x2e, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65,
+	0x72, 0x2e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x30, 0x01,
+	0x12, 0x71, 0x0a, 0x11, 0x57, 0x61, 0x74, 0x63, 0x68, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x2f, 0x2e, 0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2e,
+	0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x57, 0x61,
+	0x74, 0x63, 0x68, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e, 0x65, 0x6d, 0x6f, 0x72, 0x74, 0x61, 0x6c,
+	0x2e, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x50,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x30, 0x01, 0x42, 0x1e, 0x5a, 0x1c, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x2d, 0x74, 0x72,
+	0x61, 0x63, 0x6b, 0x65, 0x72, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65,
+	0x72, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_playertracker_watch_proto_rawDescOnce sync.Once
+	file_playertracker_watch_proto_rawDescData = file_playertracker_watch_proto_rawDesc
+)
+
+func file_playertracker_watch_proto_rawDescGZIP() []byte {
+	file_playertracker_watch_proto_rawDescOnce.Do(func() {
+		file_playertracker_watch_proto_rawDescData = protoimpl.X.CompressGZIP(file_playertracker_watch_proto_rawDescData)
+	})
+	return file_playertracker_watch_proto_rawDescData
+}
+
+var file_playertracker_watch_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_playertracker_watch_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_playertracker_watch_proto_goTypes = []interface{}{
+	(PlayerUpdate_UpdateType)(0),     // 0: emortal.playertracker.PlayerUpdate.UpdateType
+	(*WatchPlayerRequest)(nil),       // 1: emortal.playertracker.WatchPlayerRequest
+	(*PlayerUpdate)(nil),             // 2: emortal.playertracker.PlayerUpdate
+	(*WatchPlayerCountsRequest)(nil), // 3: emortal.playertracker.WatchPlayerCountsRequest
+	(*PlayerCountsUpdate)(nil),       // 4: emortal.playertracker.PlayerCountsUpdate
+	nil,                              // 5: emortal.playertracker.PlayerCountsUpdate.ServerPlayerCountsEntry
+	nil,                              // 6: emortal.playertracker.PlayerCountsUpdate.ServerTypePlayerCountsEntry
+	(*timestamppb.Timestamp)(nil),    // 7: google.protobuf.Timestamp
+}
+var file_playertracker_watch_proto_depIdxs = []int32{
+	0, // 0: emortal.playertracker.PlayerUpdate.type:type_name -> emortal.playertracker.PlayerUpdate.UpdateType
+	7, // 1: emortal.playertracker.PlayerUpdate.at:type_name -> google.protobuf.Timestamp
+	5, // 2: emortal.playertracker.PlayerCountsUpdate.server_player_counts:type_name -> emortal.playertracker.PlayerCountsUpdate.ServerPlayerCountsEntry
+	6, // 3: emortal.playertracker.PlayerCountsUpdate.server_type_player_counts:type_name -> emortal.playertracker.PlayerCountsUpdate.ServerTypePlayerCountsEntry
+	1, // 4: emortal.playertracker.PlayerWatch.WatchPlayer:input_type -> emortal.playertracker.WatchPlayerRequest
+	3, // 5: emortal.playertracker.PlayerWatch.WatchPlayerCounts:input_type -> emortal.playertracker.WatchPlayerCountsRequest
+	2, // 6: emortal.playertracker.PlayerWatch.WatchPlayer:output_type -> emortal.playertracker.PlayerUpdate
+	4, // 7: emortal.playertracker.PlayerWatch.WatchPlayerCounts:output_type -> emortal.playertracker.PlayerCountsUpdate
+	6, // [6:8] is the sub-list for method output_type
+	4, // [4:6] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_playertracker_watch_proto_init() }
+func file_playertracker_watch_proto_init() {
+	if File_playertracker_watch_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_playertracker_watch_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchPlayerRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_playertracker_watch_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PlayerUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_playertracker_watch_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchPlayerCountsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_playertracker_watch_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PlayerCountsUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_playertracker_watch_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_playertracker_watch_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_playertracker_watch_proto_goTypes,
+		DependencyIndexes: file_playertracker_watch_proto_depIdxs,
+		EnumInfos:         file_playertracker_watch_proto_enumTypes,
+		MessageInfos:      file_playertracker_watch_proto_msgTypes,
+	}.Build()
+	File_playertracker_watch_proto = out.File
+	file_playertracker_watch_proto_rawDesc = nil
+	file_playertracker_watch_proto_goTypes = nil
+	file_playertracker_watch_proto_depIdxs = nil
+}
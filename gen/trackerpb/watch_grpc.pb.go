@@ -0,0 +1,211 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.12
+// source: playertracker/watch.proto
+
+package trackerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// PlayerWatchClient is the client API for PlayerWatch service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PlayerWatchClient interface {
+	// WatchPlayer streams location updates for the players until the client cancels.
+	// The first update for each player is a SNAPSHOT of their current state, followed by updates as they connect,
+	// switch server and disconnect. An update received just after the snapshot may repeat what it already showed.
+	//
+	// If the client falls too far behind, the stream ends with RESOURCE_EXHAUSTED and the client should watch again.
+	WatchPlayer(ctx context.Context, in *WatchPlayerRequest, opts ...grpc.CallOption) (PlayerWatch_WatchPlayerClient, error)
+	// WatchPlayerCounts streams the player counts of servers and server types as they change.
+	// The first update has every count, later updates only have the counts that changed.
+	// Changes are merged so that updates are sent at most once per the configured interval.
+	WatchPlayerCounts(ctx context.Context, in *WatchPlayerCountsRequest, opts ...grpc.CallOption) (PlayerWatch_WatchPlayerCountsClient, error)
+}
+
+type playerWatchClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPlayerWatchClient(cc grpc.ClientConnInterface) PlayerWatchClient {
+	return &playerWatchClient{cc}
+}
+
+func (c *playerWatchClient) WatchPlayer(ctx context.Context, in *WatchPlayerRequest, opts ...grpc.CallOption) (PlayerWatch_WatchPlayerClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PlayerWatch_ServiceDesc.Streams[0], "/emortal.playertracker.PlayerWatch/WatchPlayer", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &playerWatchWatchPlayerClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PlayerWatch_WatchPlayerClient interface {
+	Recv() (*PlayerUpdate, error)
+	grpc.ClientStream
+}
+
+type playerWatchWatchPlayerClient struct {
+	grpc.ClientStream
+}
+
+func (x *playerWatchWatchPlayerClient) Recv() (*PlayerUpdate, error) {
+	m := new(PlayerUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *playerWatchClient) WatchPlayerCounts(ctx context.Context, in *WatchPlayerCountsRequest, opts ...grpc.CallOption) (PlayerWatch_WatchPlayerCountsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PlayerWatch_ServiceDesc.Streams[1], "/emortal.playertracker.PlayerWatch/WatchPlayerCounts", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &playerWatchWatchPlayerCountsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PlayerWatch_WatchPlayerCountsClient interface {
+	Recv() (*PlayerCountsUpdate, error)
+	grpc.ClientStream
+}
+
+type playerWatchWatchPlayerCountsClient struct {
+	grpc.ClientStream
+}
+
+func (x *playerWatchWatchPlayerCountsClient) Recv() (*PlayerCountsUpdate, error) {
+	m := new(PlayerCountsUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PlayerWatchServer is the server API for PlayerWatch service.
+// All implementations must embed UnimplementedPlayerWatchServer
+// for forward compatibility
+type PlayerWatchServer interface {
+	// WatchPlayer streams location updates for the players until the client cancels.
+	// The first update for each player is a SNAPSHOT of their current state, followed by updates as they connect,
+	// switch server and disconnect. An update received just after the snapshot may repeat what it already showed.
+	//
+	// If the client falls too far behind, the stream ends with RESOURCE_EXHAUSTED and the client should watch again.
+	WatchPlayer(*WatchPlayerRequest, PlayerWatch_WatchPlayerServer) error
+	// WatchPlayerCounts streams the player counts of servers and server types as they change.
+	// The first update has every count, later updates only have the counts that changed.
+	// Changes are merged so that updates are sent at most once per the configured interval.
+	WatchPlayerCounts(*WatchPlayerCountsRequest, PlayerWatch_WatchPlayerCountsServer) error
+	mustEmbedUnimplementedPlayerWatchServer()
+}
+
+// UnimplementedPlayerWatchServer must be embedded to have forward compatible implementations.
+type UnimplementedPlayerWatchServer struct {
+}
+
+func (UnimplementedPlayerWatchServer) WatchPlayer(*WatchPlayerRequest, PlayerWatch_WatchPlayerServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPlayer not implemented")
+}
+func (UnimplementedPlayerWatchServer) WatchPlayerCounts(*WatchPlayerCountsRequest, PlayerWatch_WatchPlayerCountsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPlayerCounts not implemented")
+}
+func (UnimplementedPlayerWatchServer) mustEmbedUnimplementedPlayerWatchServer() {}
+
+// UnsafePlayerWatchServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PlayerWatchServer will
+// result in compilation errors.
+type UnsafePlayerWatchServer interface {
+	mustEmbedUnimplementedPlayerWatchServer()
+}
+
+func RegisterPlayerWatchServer(s grpc.ServiceRegistrar, srv PlayerWatchServer) {
+	s.RegisterService(&PlayerWatch_ServiceDesc, srv)
+}
+
+func _PlayerWatch_WatchPlayer_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPlayerRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PlayerWatchServer).WatchPlayer(m, &playerWatchWatchPlayerServer{stream})
+}
+
+type PlayerWatch_WatchPlayerServer interface {
+	Send(*PlayerUpdate) error
+	grpc.ServerStream
+}
+
+type playerWatchWatchPlayerServer struct {
+	grpc.ServerStream
+}
+
+func (x *playerWatchWatchPlayerServer) Send(m *PlayerUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _PlayerWatch_WatchPlayerCounts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPlayerCountsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PlayerWatchServer).WatchPlayerCounts(m, &playerWatchWatchPlayerCountsServer{stream})
+}
+
+type PlayerWatch_WatchPlayerCountsServer interface {
+	Send(*PlayerCountsUpdate) error
+	grpc.ServerStream
+}
+
+type playerWatchWatchPlayerCountsServer struct {
+	grpc.ServerStream
+}
+
+func (x *playerWatchWatchPlayerCountsServer) Send(m *PlayerCountsUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// PlayerWatch_ServiceDesc is the grpc.ServiceDesc for PlayerWatch service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PlayerWatch_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "emortal.playertracker.PlayerWatch",
+	HandlerType: (*PlayerWatchServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPlayer",
+			Handler:       _PlayerWatch_WatchPlayer_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchPlayerCounts",
+			Handler:       _PlayerWatch_WatchPlayerCounts_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "playertracker/watch.proto",
+}
@@ -2,62 +2,222 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/emortalmc/proto-specs/gen/go/grpc/playertracker"
 	grpczap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	grpcprometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"net"
+	"net/http"
+	"player-tracker/gen/trackerpb"
 	"player-tracker/internal/config"
-	"player-tracker/internal/rabbitmq"
-	"player-tracker/internal/rabbitmq/listener"
+	"player-tracker/internal/fleet"
+	"player-tracker/internal/healthcheck"
+	"player-tracker/internal/listener"
+	"player-tracker/internal/listener/natssource"
+	"player-tracker/internal/listener/rabbitmqsource"
+	"player-tracker/internal/metrics"
 	"player-tracker/internal/repository"
 	"player-tracker/internal/service"
+	"player-tracker/internal/watch"
+	"time"
 )
 
+// healthCheckInterval is how often the dependencies are checked to report the gRPC health status
+const healthCheckInterval = 5 * time.Second
+
 func Run(ctx context.Context, cfg *config.Config, logger *zap.SugaredLogger) {
-	repo, err := repository.NewMongoRepository(ctx, cfg.MongoDB)
+	sessionStore, err := repository.SessionStore(cfg)
+	if err != nil {
+		logger.Fatalw("invalid session store", "error", err)
+	}
+	if cfg.Repository == repository.TypeMongoDB || cfg.Repository == "" || sessionStore == repository.TypeMongoDB {
+		Migrate(ctx, cfg, logger)
+	}
+
+	fleets, err := fleet.NewRegistry(cfg.Fleets)
+	if err != nil {
+		logger.Fatalw("failed to create fleet registry", "error", err)
+	}
+	config.WatchGlobalConfig(func(cfg *config.Config, err error) {
+		if err == nil {
+			err = fleets.Update(cfg.Fleets)
+		}
+		if err != nil {
+			logger.Errorw("failed to reload fleets, keeping the previous fleets", "error", err)
+			return
+		}
+		logger.Infow("reloaded fleets", "fleets", cfg.Fleets)
+	})
+
+	repo, err := repository.NewRepository(ctx, cfg)
 	if err != nil {
 		logger.Fatalw("failed to create repository", err)
 	}
+	logger.Infow("created repository", "type", cfg.Repository)
 
-	// NOTE: We can share a RabbitMQ connection, but it is not recommended to share a channel
-	rabbitConn, err := rabbitmq.NewConnection(cfg.RabbitMQ)
+	sessions, err := repository.NewSessionRepository(ctx, cfg, repo)
 	if err != nil {
-		logger.Fatalw("failed to create rabbitmq connection", "error", err)
+		logger.Fatalw("failed to create session repository", "error", err)
 	}
+	logger.Infow("created session repository", "type", sessionStore)
 
-	err = listener.NewRabbitMQListener(logger, repo, rabbitConn)
+	hub := watch.NewHub()
+
+	source, err := newSource(logger, cfg)
 	if err != nil {
-		logger.Fatalw("failed to create rabbitmq listener", "error", err)
+		logger.Fatalw("failed to create listener source", "error", err)
 	}
-	logger.Infow("connected to RabbitMQ", "host", cfg.RabbitMQ.Host)
+	playerListener, err := listener.NewListener(logger, cfg.Listener, source, repo, sessions, hub)
+	if err != nil {
+		logger.Fatalw("failed to create listener", "source", cfg.Listener.Source, "error", err)
+	}
+	logger.Infow("created listener", "source", cfg.Listener.Source)
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Port))
 	if err != nil {
 		logger.Fatalw("failed to listen", "error", err)
 	}
 
+	levels := grpczap.WithLevels(func(code codes.Code) zapcore.Level {
+		if code != codes.Internal && code != codes.Unavailable && code != codes.Unknown {
+			return zapcore.DebugLevel
+		} else {
+			return zapcore.ErrorLevel
+		}
+	})
 	s := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
-			grpczap.UnaryServerInterceptor(logger.Desugar(), grpczap.WithLevels(func(code codes.Code) zapcore.Level {
-				if code != codes.Internal && code != codes.Unavailable && code != codes.Unknown {
-					return zapcore.DebugLevel
-				} else {
-					return zapcore.ErrorLevel
-				}
-			})),
+			grpczap.UnaryServerInterceptor(logger.Desugar(), levels),
 			grpcprometheus.UnaryServerInterceptor,
 		),
+		grpc.ChainStreamInterceptor(
+			grpczap.StreamServerInterceptor(logger.Desugar(), levels),
+			grpcprometheus.StreamServerInterceptor,
+		),
 	)
-	playertracker.RegisterPlayerTrackerServer(s, service.NewPlayerTrackerService(repo))
-	logger.Infow("listening on port", "port", cfg.Port)
+	playertracker.RegisterPlayerTrackerServer(s, service.NewPlayerTrackerService(repo, fleets))
+	trackerpb.RegisterPlayerHistoryServer(s, service.NewPlayerHistoryService(repo, sessions))
+	trackerpb.RegisterPlayerWatchServer(s, service.NewPlayerWatchService(repo, fleets, hub, cfg.CountUpdateInterval))
+	trackerpb.RegisterPlayerTrackerAdminServer(s, service.NewPlayerTrackerAdminService(playerListener))
+
+	healthServer := health.NewServer()
+	var services []string
+	for name := range s.GetServiceInfo() {
+		services = append(services, name)
+	}
+	grpc_health_v1.RegisterHealthServer(s, healthServer)
+	checker := healthcheck.NewChecker(logger, healthServer, services, map[string]healthcheck.Check{
+		"repository":         repo.Ping,
+		"session_repository": sessions.Ping,
+		cfg.Listener.Source: func(_ context.Context) error {
+			if !playerListener.Consuming() {
+				return errors.New("not consuming messages")
+			}
+			return nil
+		},
+	})
+	go checker.Run(ctx, healthCheckInterval)
+
+	if cfg.Reflection {
+		reflection.Register(s)
+	}
+
+	// Must be after the services are registered so their metrics are initialised
+	grpcprometheus.Register(s)
+	prometheus.MustRegister(metrics.NewRepositoryCollector(repo, fleets))
+
+	metricsServer := metrics.NewServer(cfg.MetricsPort)
+	go func() {
+		logger.Infow("serving metrics", "port", cfg.MetricsPort)
+		err := metricsServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatalw("failed to serve metrics", "error", err)
+		}
+	}()
+
+	go func() {
+		logger.Infow("listening on port", "port", cfg.Port)
+		err := s.Serve(lis)
+		if err != nil {
+			logger.Fatalw("failed to serve", "error", err)
+		}
+	}()
 
-	err = s.Serve(lis)
+	<-ctx.Done()
+	logger.Infow("shutting down", "timeout", cfg.ShutdownTimeout)
+	healthServer.Shutdown()
+
+	// The deadline is shared by every step, so the whole shutdown takes at most ShutdownTimeout
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Messages are stopped first so nothing is written once the connections start closing
+	if err := playerListener.Stop(shutdownCtx); err != nil {
+		logger.Errorw("failed to stop listener", "error", err)
+	}
+
+	// Closing the hub ends the watch streams, which would otherwise run until the deadline
+	hub.Close()
+	gracefulStop(shutdownCtx, s)
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		logger.Errorw("failed to stop metrics server", "error", err)
+	}
+
+	if err := repo.Close(shutdownCtx); err != nil {
+		logger.Errorw("failed to close repository", "error", err)
+	}
+	if err := sessions.Close(shutdownCtx); err != nil {
+		logger.Errorw("failed to close session repository", "error", err)
+	}
+	logger.Infow("shut down")
+}
+
+// newSource creates the source the listener consumes player messages from, chosen by cfg.Listener.Source
+func newSource(logger *zap.SugaredLogger, cfg *config.Config) (listener.Source, error) {
+	switch cfg.Listener.Source {
+	case listener.SourceRabbitMQ:
+		return rabbitmqsource.New(logger, cfg.RabbitMQ, cfg.Listener), nil
+	case listener.SourceNATS:
+		return natssource.New(logger, cfg.NATS, cfg.Listener), nil
+	default:
+		return nil, fmt.Errorf("unknown listener source %q", cfg.Listener.Source)
+	}
+}
+
+// gracefulStop waits for in-progress RPCs to finish, cancelling any that are still running when ctx ends.
+func gracefulStop(ctx context.Context, s *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.Stop()
+	}
+}
+
+// Migrate applies any pending mongo migrations, exiting if they fail
+func Migrate(ctx context.Context, cfg *config.Config, logger *zap.SugaredLogger) {
+	applied, err := repository.MigrateMongo(ctx, cfg.MongoDB)
 	if err != nil {
-		logger.Fatalw("failed to serve", "error", err)
+		logger.Fatalw("failed to migrate mongo", "error", err, "applied", applied)
+	}
+
+	if len(applied) == 0 {
+		logger.Infow("mongo is up to date")
+	} else {
+		logger.Infow("applied mongo migrations", "versions", applied)
 	}
 }
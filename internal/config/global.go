@@ -1,32 +1,157 @@
 package config
 
 import (
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
+	"player-tracker/internal/fleet"
 	"strings"
+	"time"
 )
 
 type Config struct {
+	Listener    *ListenerConfig `yaml:"listener"`
 	RabbitMQ    *RabbitMQConfig `yaml:"rabbitmq"`
+	NATS        *NATSConfig     `yaml:"nats"`
 	MongoDB     *MongoDBConfig  `yaml:"mongodb"`
+	Redis       *RedisConfig    `yaml:"redis"`
 	Development bool            `yaml:"debug"`
 
+	// Repository is the storage backend to use, either "mongodb", "redis" or "memory"
+	Repository string `yaml:"repository"`
+	// Sessions is where session history is kept, either "mongodb" or "memory".
+	// It defaults to the same as Repository, so must be set with the redis repository.
+	Sessions string `yaml:"sessions"`
+
+	// Fleets maps server type names (e.g. lobby, block_sumo) to the fleets that run them.
+	// Each configured server type replaces its default, see fleet.DefaultMapping.
+	Fleets map[string][]string `yaml:"fleets"`
+
+	// CountUpdateInterval is the minimum time between player count updates sent to a WatchPlayerCounts stream
+	CountUpdateInterval time.Duration `yaml:"countUpdateInterval"`
+
 	Port uint16 `yaml:"port"`
+	// MetricsPort is the port Prometheus metrics are served on, at /metrics
+	MetricsPort uint16 `yaml:"metricsPort"`
+
+	// Reflection enables gRPC server reflection, for tools such as grpcurl
+	Reflection bool `yaml:"reflection"`
+
+	// ShutdownTimeout is how long in-progress messages and RPCs are given to finish when shutting down
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
 }
 
 type RabbitMQConfig struct {
 	Host     string `yaml:"host"`
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
+
+	// Exchange is the exchange player messages are published to, routed by message type.
+	// It is declared with ExchangeType if it doesn't exist.
+	Exchange     string `yaml:"exchange"`
+	ExchangeType string `yaml:"exchangeType"`
+	// Queue is the durable queue the listener consumes from, declared and bound to Exchange at startup
+	Queue string `yaml:"queue"`
+	// DeadLetterExchange receives messages that can't be handled, it is optional and they are dropped if empty
+	DeadLetterExchange string `yaml:"deadLetterExchange"`
+	// EventsExchange is the topic exchange the tracker publishes PlayerLocationChangedMessages to once they are
+	// written, routed by message type. They are kept in the {Queue}.events queue until they are consumed, and other
+	// queues can be bound to it too. It is optional and no events are published if empty.
+	EventsExchange string `yaml:"eventsExchange"`
+}
+
+// ListenerConfig configures how player messages are consumed, whichever broker they come from
+type ListenerConfig struct {
+	// Source is the broker player messages are consumed from, either "rabbitmq" or "nats"
+	Source string `yaml:"source"`
+
+	// Prefetch is how many unacknowledged messages are received at once, shared between the Workers.
+	// Each player's messages are handled by the same worker, in order.
+	Prefetch int `yaml:"prefetch"`
+	Workers  int `yaml:"workers"`
+
+	// DedupeCapacity is how many of the most recently applied message IDs are kept to drop redelivered duplicates
+	DedupeCapacity int `yaml:"dedupeCapacity"`
+
+	// UnknownTypes is what happens to messages of types the listener doesn't handle, either "reject" to
+	// dead-letter them or "ack" to ignore them
+	UnknownTypes string `yaml:"unknownTypes"`
+
+	// MaxAttempts is how many times a message is handled before it is dead-lettered
+	MaxAttempts int `yaml:"maxAttempts"`
+	// RetryBackoff is the delay before a failed message is retried, doubling with each attempt
+	RetryBackoff time.Duration `yaml:"retryBackoff"`
+
+	// ProxyLease is how long after a proxy's last heartbeat its players are expired
+	ProxyLease time.Duration `yaml:"proxyLease"`
+	// LeaseCheckInterval is how often proxy leases are checked for expiry, 0 disables expiring them
+	LeaseCheckInterval time.Duration `yaml:"leaseCheckInterval"`
+}
+
+// NATSConfig configures consuming player messages from NATS JetStream, used when the listener's source is "nats".
+// Streams that don't exist are created at startup.
+type NATSConfig struct {
+	URL string `yaml:"url"`
+
+	// Stream is the stream player messages are consumed from. They are published to {Subject}.{type},
+	// where type is the full name of the message, e.g. mc.proxy.emortal.message.PlayerConnectMessage.
+	Stream  string `yaml:"stream"`
+	Subject string `yaml:"subject"`
+	// Consumer is the name of the durable consumer, shared by all the tracker's replicas
+	Consumer string `yaml:"consumer"`
+
+	// TrackerStream is the stream that stores the messages the tracker publishes, on EventsSubject and DeadLetterSubject
+	TrackerStream string `yaml:"trackerStream"`
+	// EventsSubject is where PlayerLocationChangedMessages are published once they are written, as
+	// {EventsSubject}.{type}. It is optional and no events are published if empty.
+	EventsSubject string `yaml:"eventsSubject"`
+	// DeadLetterSubject receives messages that can't be handled, as {DeadLetterSubject}.{type}.
+	// It is optional and they are dropped if empty.
+	DeadLetterSubject string `yaml:"deadLetterSubject"`
 }
 
 type MongoDBConfig struct {
 	URI string `yaml:"uri"`
 }
 
+type RedisConfig struct {
+	Address  string `yaml:"address"`
+	Password string `yaml:"password"`
+	Database int    `yaml:"database"`
+	// Cluster is whether Address is a node of a Redis Cluster, the rest of the cluster is discovered from it.
+	// Database must be 0 as a cluster only has the one.
+	Cluster bool `yaml:"cluster"`
+}
+
 func LoadGlobalConfig() (config *Config, err error) {
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
 
+	viper.SetDefault("repository", "mongodb")
+	viper.SetDefault("countUpdateInterval", time.Second)
+	viper.SetDefault("metricsPort", 8081)
+	viper.SetDefault("rabbitmq.exchange", "mc:proxy:all")
+	viper.SetDefault("rabbitmq.exchangeType", "topic")
+	viper.SetDefault("rabbitmq.queue", "player-tracker:all")
+	viper.SetDefault("rabbitmq.deadLetterExchange", "player-tracker.dead-letter")
+	viper.SetDefault("rabbitmq.eventsExchange", "player-tracker:events")
+	viper.SetDefault("listener.source", "rabbitmq")
+	viper.SetDefault("listener.prefetch", 200)
+	viper.SetDefault("listener.workers", 16)
+	viper.SetDefault("listener.dedupeCapacity", 10000)
+	viper.SetDefault("listener.unknownTypes", "reject")
+	viper.SetDefault("listener.maxAttempts", 5)
+	viper.SetDefault("listener.retryBackoff", time.Second)
+	viper.SetDefault("listener.proxyLease", 30*time.Second)
+	viper.SetDefault("listener.leaseCheckInterval", 5*time.Second)
+	viper.SetDefault("nats.url", "nats://localhost:4222")
+	viper.SetDefault("nats.stream", "MC_PROXY")
+	viper.SetDefault("nats.subject", "mc.proxy")
+	viper.SetDefault("nats.consumer", "player-tracker")
+	viper.SetDefault("nats.trackerStream", "PLAYER_TRACKER")
+	viper.SetDefault("nats.eventsSubject", "player-tracker.events")
+	viper.SetDefault("nats.deadLetterSubject", "player-tracker.dead-letter")
+	viper.SetDefault("shutdownTimeout", 30*time.Second)
+
 	viper.SetConfigName("config")
 	viper.AddConfigPath(".")
 
@@ -35,10 +160,34 @@ func LoadGlobalConfig() (config *Config, err error) {
 		return
 	}
 
+	return unmarshal()
+}
+
+// WatchGlobalConfig reloads the config whenever the config file changes, calling onChange with the result.
+// LoadGlobalConfig must have been called first.
+func WatchGlobalConfig(onChange func(config *Config, err error)) {
+	viper.OnConfigChange(func(_ fsnotify.Event) {
+		onChange(unmarshal())
+	})
+	viper.WatchConfig()
+}
+
+func unmarshal() (config *Config, err error) {
 	err = viper.Unmarshal(&config)
 	if err != nil {
 		return
 	}
 
+	// Server types that aren't configured keep their default fleets.
+	// This isn't a viper default as viper would replace the whole map rather than merge it.
+	if config.Fleets == nil {
+		config.Fleets = make(map[string][]string, len(fleet.DefaultMapping))
+	}
+	for serverType, fleets := range fleet.DefaultMapping {
+		if _, ok := config.Fleets[serverType]; !ok {
+			config.Fleets[serverType] = fleets
+		}
+	}
+
 	return
 }
@@ -0,0 +1,99 @@
+package fleet
+
+import (
+	"fmt"
+	"github.com/emortalmc/proto-specs/gen/go/model/common"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultMapping is the fleets of each server type used when none are configured.
+// Keys are server type names, case-insensitive.
+var DefaultMapping = map[string][]string{
+	"lobby":           {"lobby"},
+	"marathon":        {"marathon"},
+	"block_sumo":      {"block-sumo"},
+	"parkourtag":      {"parkourtag"},
+	"lazertag":        {"lazertag"},
+	"holey_moley":     {"holey-moley"},
+	"marathon_racing": {"marathon-racing"},
+	"battle":          {"battle"},
+	"minesweeper":     {"minesweeper"},
+}
+
+// Registry maps server types to the fleets that run them.
+// It is safe for concurrent use and can be updated while in use.
+type Registry struct {
+	lock   sync.RWMutex
+	fleets map[common.ServerType][]string
+}
+
+// NewRegistry creates a Registry from a mapping of server type name to fleet names, see Update.
+func NewRegistry(mapping map[string][]string) (*Registry, error) {
+	registry := &Registry{}
+	if err := registry.Update(mapping); err != nil {
+		return nil, err
+	}
+
+	return registry, nil
+}
+
+// Update replaces the whole mapping of the registry.
+// Keys are server type names, case-insensitive. PROXY can't be mapped as it counts every player.
+// If the mapping is invalid an error is returned and the registry is left unchanged.
+func (r *Registry) Update(mapping map[string][]string) error {
+	fleets := make(map[common.ServerType][]string, len(mapping))
+	for name, names := range mapping {
+		value, ok := common.ServerType_value[strings.ToUpper(name)]
+		if !ok {
+			return fmt.Errorf("unknown server type %s", name)
+		}
+
+		serverType := common.ServerType(value)
+		if serverType == common.ServerType_PROXY {
+			return fmt.Errorf("server type %s can't have fleets", name)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("server type %s has no fleets", name)
+		}
+
+		fleets[serverType] = append([]string{}, names...)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.fleets = fleets
+	return nil
+}
+
+// Fleets returns the fleets of the server type, or false if the server type has none
+func (r *Registry) Fleets(serverType common.ServerType) ([]string, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	fleets, ok := r.fleets[serverType]
+	return fleets, ok
+}
+
+// AllFleets returns every fleet of every server type, sorted and without duplicates
+func (r *Registry) AllFleets() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	seen := make(map[string]struct{})
+	var all []string
+	for _, fleets := range r.fleets {
+		for _, name := range fleets {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			all = append(all, name)
+		}
+	}
+
+	sort.Strings(all)
+	return all
+}
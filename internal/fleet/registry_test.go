@@ -0,0 +1,93 @@
+package fleet
+
+import (
+	"github.com/emortalmc/proto-specs/gen/go/model/common"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewRegistry_Default(t *testing.T) {
+	registry, err := NewRegistry(DefaultMapping)
+	assert.NoError(t, err)
+
+	fleets, ok := registry.Fleets(common.ServerType_BLOCK_SUMO)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"block-sumo"}, fleets)
+
+	_, ok = registry.Fleets(common.ServerType_PROXY)
+	assert.False(t, ok)
+}
+
+func TestRegistry_Update(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping map[string][]string
+		want    map[common.ServerType][]string
+		wantErr bool
+	}{
+		{
+			name:    "multiple_fleets",
+			mapping: map[string][]string{"lobby": {"lobby", "lobby-event"}},
+			want:    map[common.ServerType][]string{common.ServerType_LOBBY: {"lobby", "lobby-event"}},
+		},
+		{
+			name:    "case_insensitive",
+			mapping: map[string][]string{"Marathon_Racing": {"marathon-racing"}},
+			want:    map[common.ServerType][]string{common.ServerType_MARATHON_RACING: {"marathon-racing"}},
+		},
+		{
+			name:    "unknown_server_type",
+			mapping: map[string][]string{"bedwars": {"bedwars"}},
+			wantErr: true,
+		},
+		{
+			name:    "proxy",
+			mapping: map[string][]string{"proxy": {"proxy"}},
+			wantErr: true,
+		},
+		{
+			name:    "no_fleets",
+			mapping: map[string][]string{"lobby": {}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry, err := NewRegistry(DefaultMapping)
+			assert.NoError(t, err)
+
+			err = registry.Update(test.mapping)
+			if test.wantErr {
+				assert.Error(t, err)
+
+				// A failed update must leave the previous mapping in place
+				fleets, ok := registry.Fleets(common.ServerType_LOBBY)
+				assert.True(t, ok)
+				assert.Equal(t, []string{"lobby"}, fleets)
+				return
+			}
+			assert.NoError(t, err)
+
+			for serverType, want := range test.want {
+				got, ok := registry.Fleets(serverType)
+				assert.True(t, ok)
+				assert.Equal(t, want, got)
+			}
+
+			// The whole mapping is replaced
+			_, ok := registry.Fleets(common.ServerType_BATTLE)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestRegistry_AllFleets(t *testing.T) {
+	registry, err := NewRegistry(map[string][]string{
+		"lobby":    {"lobby", "lobby-event"},
+		"marathon": {"marathon", "lobby-event"},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"lobby", "lobby-event", "marathon"}, registry.AllFleets())
+}
@@ -0,0 +1,88 @@
+package healthcheck
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"sort"
+	"time"
+)
+
+// Check returns an error if a dependency isn't usable
+type Check func(ctx context.Context) error
+
+// Checker reports the result of its checks as the serving status of a gRPC health server.
+// The services are NOT_SERVING until every check has passed, and again whenever any check fails.
+type Checker struct {
+	logger   *zap.SugaredLogger
+	server   *health.Server
+	services []string
+	checks   map[string]Check
+
+	// failing is the names of the checks that failed last time, so only changes are logged
+	failing map[string]struct{}
+}
+
+// NewChecker creates a Checker that sets the status of the services on the server.
+// The empty service name, the status of the whole server, is always included.
+func NewChecker(logger *zap.SugaredLogger, server *health.Server, services []string, checks map[string]Check) *Checker {
+	services = append([]string{""}, services...)
+	for _, service := range services {
+		server.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}
+
+	return &Checker{
+		logger:   logger,
+		server:   server,
+		services: services,
+		checks:   checks,
+		failing:  make(map[string]struct{}),
+	}
+}
+
+// Run checks every interval until ctx ends
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check runs every check once and updates the serving status
+func (c *Checker) Check(ctx context.Context) {
+	names := make([]string, 0, len(c.checks))
+	for name := range c.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	status := grpc_health_v1.HealthCheckResponse_SERVING
+	for _, name := range names {
+		err := c.checks[name](ctx)
+
+		_, wasFailing := c.failing[name]
+		if err != nil {
+			status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+			c.failing[name] = struct{}{}
+			if !wasFailing {
+				c.logger.Warnw("health check failed", "check", name, "error", err)
+			}
+		} else if wasFailing {
+			delete(c.failing, name)
+			c.logger.Infow("health check recovered", "check", name)
+		}
+	}
+
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, status)
+	}
+}
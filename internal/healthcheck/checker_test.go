@@ -0,0 +1,76 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"testing"
+)
+
+func TestChecker_Check(t *testing.T) {
+	ctx := context.Background()
+	server := health.NewServer()
+
+	var mongoErr error
+	consuming := false
+	checker := NewChecker(zap.NewNop().Sugar(), server, []string{"emortal.grpc.playertracker.PlayerTracker"}, map[string]Check{
+		"mongodb": func(_ context.Context) error { return mongoErr },
+		"rabbitmq": func(_ context.Context) error {
+			if !consuming {
+				return errors.New("not consuming")
+			}
+			return nil
+		},
+	})
+
+	statusOf := func(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		res, err := server.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		assert.NoError(t, err)
+		return res.Status
+	}
+
+	// Nothing is serving before the first check
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, statusOf(""))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, statusOf("emortal.grpc.playertracker.PlayerTracker"))
+
+	tests := []struct {
+		name      string
+		mongoErr  error
+		consuming bool
+		want      grpc_health_v1.HealthCheckResponse_ServingStatus
+	}{
+		{
+			name: "consumer_not_attached",
+			want: grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		},
+		{
+			name:      "all_passing",
+			consuming: true,
+			want:      grpc_health_v1.HealthCheckResponse_SERVING,
+		},
+		{
+			name:      "mongo_lost",
+			mongoErr:  errors.New("server selection timeout"),
+			consuming: true,
+			want:      grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		},
+		{
+			name:      "mongo_recovered",
+			consuming: true,
+			want:      grpc_health_v1.HealthCheckResponse_SERVING,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mongoErr, consuming = test.mongoErr, test.consuming
+			checker.Check(ctx)
+
+			assert.Equal(t, test.want, statusOf(""))
+			assert.Equal(t, test.want, statusOf("emortal.grpc.playertracker.PlayerTracker"))
+		})
+	}
+}
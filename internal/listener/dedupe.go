@@ -0,0 +1,60 @@
+package listener
+
+import (
+	"container/list"
+	"sync"
+)
+
+// messageIdCache holds the most recently added message IDs, evicting the oldest once it is full.
+// It is safe for concurrent use.
+type messageIdCache struct {
+	lock     sync.Mutex
+	capacity int
+	// order holds the IDs, newest at the front
+	order *list.List
+	ids   map[string]*list.Element
+}
+
+func newMessageIdCache(capacity int) *messageIdCache {
+	return &messageIdCache{
+		capacity: capacity,
+		order:    list.New(),
+		ids:      make(map[string]*list.Element, capacity),
+	}
+}
+
+// Contains returns whether the ID has been added and not evicted.
+// Messages without an ID can't be deduplicated, so the empty ID is never contained.
+func (c *messageIdCache) Contains(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, ok := c.ids[id]
+	return ok
+}
+
+// Add adds the ID, evicting the oldest ID if the cache is full. Empty IDs are ignored.
+func (c *messageIdCache) Add(id string) {
+	if id == "" || c.capacity <= 0 {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.ids[id]; ok {
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.ids[id] = c.order.PushFront(id)
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.ids, oldest.Value.(string))
+	}
+}
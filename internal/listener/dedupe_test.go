@@ -0,0 +1,26 @@
+package listener
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMessageIdCache(t *testing.T) {
+	cache := newMessageIdCache(2)
+
+	cache.Add("1")
+	cache.Add("2")
+	assert.True(t, cache.Contains("1"))
+	assert.True(t, cache.Contains("2"))
+
+	// Re-adding 1 makes 2 the oldest, so it's evicted by 3
+	cache.Add("1")
+	cache.Add("3")
+	assert.True(t, cache.Contains("1"))
+	assert.False(t, cache.Contains("2"))
+	assert.True(t, cache.Contains("3"))
+
+	// Messages without an ID are never duplicates
+	cache.Add("")
+	assert.False(t, cache.Contains(""))
+}
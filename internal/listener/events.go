@@ -0,0 +1,56 @@
+package listener
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/repository/model"
+	"time"
+)
+
+// LocationChangedType is the type of published PlayerLocationChangedMessages, the full name of the proto message
+var LocationChangedType = messageType(&trackerpb.PlayerLocationChangedMessage{})
+
+// messageType returns the type of the message, the full name of its proto message
+func messageType(msg Message) string {
+	return string(msg.ProtoReflect().Descriptor().FullName())
+}
+
+// newLocationChanged creates the event for a change to a player's location, made by the message with the given ID.
+// previous is the player before the change, or nil if they were offline,
+// and current is the player after it, or nil if they disconnected.
+func newLocationChanged(reason trackerpb.PlayerLocationChangedMessage_Reason, playerId uuid.UUID, username string,
+	previous *model.Player, current *model.Player, at time.Time, messageId string) *trackerpb.PlayerLocationChangedMessage {
+	event := &trackerpb.PlayerLocationChangedMessage{
+		PlayerId: playerId.String(),
+		Username: username,
+		Reason:   reason,
+		At:       timestamppb.New(at),
+		// The player was last written by this message, so this is a redelivery of a change that was already applied.
+		// Times can't be compared for this, as several events can happen at the same time.
+		Replayed: messageId != "" && previous != nil && previous.LastMessageId == messageId,
+	}
+
+	if previous != nil && !event.Replayed {
+		event.Previous = &trackerpb.PlayerLocation{ServerId: previous.GameServerId, ProxyId: previous.ProxyId}
+	}
+	if current != nil {
+		event.Current = &trackerpb.PlayerLocation{ServerId: current.GameServerId, ProxyId: current.ProxyId}
+	}
+
+	return event
+}
+
+// publishEvent publishes the event, returning once the source has persisted it, so the message that caused it is
+// only acked once the event can't be lost. messageId is the ID of that message, which is reused for deduplication.
+func (l *playerListener) publishEvent(messageId string, event *trackerpb.PlayerLocationChangedMessage) error {
+	if messageId == "" {
+		messageId = uuid.NewString()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return l.source.Publish(ctx, messageId, event)
+}
@@ -0,0 +1,165 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"github.com/emortalmc/proto-specs/gen/go/message/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/repository/model"
+	"testing"
+	"time"
+)
+
+func TestNewLocationChanged(t *testing.T) {
+	playerId := uuid.New()
+	at := time.UnixMilli(1680000000000)
+
+	lobby := &model.Player{Id: playerId, Username: "Expectational", ProxyId: "proxy-sdgwsd-235eax",
+		GameServerId: "lobby-z24523-sdhbsd", LastEventAt: at.Add(-time.Second), LastMessageId: "1"}
+	sumo := &model.Player{Id: playerId, Username: "Expectational", ProxyId: "proxy-sdgwsd-235eax",
+		GameServerId: "block-sumo-2ndkfs-dfd2x", LastEventAt: at, LastMessageId: "2"}
+
+	tests := []struct {
+		name      string
+		reason    trackerpb.PlayerLocationChangedMessage_Reason
+		previous  *model.Player
+		current   *model.Player
+		messageId string
+		want      *trackerpb.PlayerLocationChangedMessage
+	}{
+		{
+			name:      "connected",
+			reason:    trackerpb.PlayerLocationChangedMessage_CONNECTED,
+			current:   &model.Player{ProxyId: "proxy-sdgwsd-235eax", LastEventAt: at, LastMessageId: "1"},
+			messageId: "1",
+			want: &trackerpb.PlayerLocationChangedMessage{
+				Reason:  trackerpb.PlayerLocationChangedMessage_CONNECTED,
+				Current: &trackerpb.PlayerLocation{ProxyId: "proxy-sdgwsd-235eax"},
+			},
+		},
+		{
+			name:      "switched_server",
+			reason:    trackerpb.PlayerLocationChangedMessage_SWITCHED_SERVER,
+			previous:  lobby,
+			current:   sumo,
+			messageId: "2",
+			want: &trackerpb.PlayerLocationChangedMessage{
+				Reason:   trackerpb.PlayerLocationChangedMessage_SWITCHED_SERVER,
+				Previous: &trackerpb.PlayerLocation{ServerId: "lobby-z24523-sdhbsd", ProxyId: "proxy-sdgwsd-235eax"},
+				Current:  &trackerpb.PlayerLocation{ServerId: "block-sumo-2ndkfs-dfd2x", ProxyId: "proxy-sdgwsd-235eax"},
+			},
+		},
+		{
+			name:      "replayed",
+			reason:    trackerpb.PlayerLocationChangedMessage_SWITCHED_SERVER,
+			previous:  sumo,
+			current:   sumo,
+			messageId: "2",
+			want: &trackerpb.PlayerLocationChangedMessage{
+				Reason:   trackerpb.PlayerLocationChangedMessage_SWITCHED_SERVER,
+				Current:  &trackerpb.PlayerLocation{ServerId: "block-sumo-2ndkfs-dfd2x", ProxyId: "proxy-sdgwsd-235eax"},
+				Replayed: true,
+			},
+		},
+		{
+			// Another message at the same time as the player's last one isn't a replay of it
+			name:      "same_time",
+			reason:    trackerpb.PlayerLocationChangedMessage_SWITCHED_SERVER,
+			previous:  sumo,
+			current:   lobby,
+			messageId: "3",
+			want: &trackerpb.PlayerLocationChangedMessage{
+				Reason:   trackerpb.PlayerLocationChangedMessage_SWITCHED_SERVER,
+				Previous: &trackerpb.PlayerLocation{ServerId: "block-sumo-2ndkfs-dfd2x", ProxyId: "proxy-sdgwsd-235eax"},
+				Current:  &trackerpb.PlayerLocation{ServerId: "lobby-z24523-sdhbsd", ProxyId: "proxy-sdgwsd-235eax"},
+			},
+		},
+		{
+			name:     "disconnected",
+			reason:   trackerpb.PlayerLocationChangedMessage_DISCONNECTED,
+			previous: lobby,
+			want: &trackerpb.PlayerLocationChangedMessage{
+				Reason:   trackerpb.PlayerLocationChangedMessage_DISCONNECTED,
+				Previous: &trackerpb.PlayerLocation{ServerId: "lobby-z24523-sdhbsd", ProxyId: "proxy-sdgwsd-235eax"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.want.PlayerId = playerId.String()
+			test.want.Username = "Expectational"
+			test.want.At = timestamppb.New(at)
+
+			got := newLocationChanged(test.reason, playerId, "Expectational", test.previous, test.current, at,
+				test.messageId)
+			assert.True(t, proto.Equal(test.want, got), "got %v", got)
+		})
+	}
+}
+
+// A message redelivered after its write was applied, because its event couldn't be published, replays the event.
+// The session writes are repeated in case they were what failed, without duplicating the session or its hops.
+func TestListener_ReplayedEvents(t *testing.T) {
+	playerId := uuid.New()
+	at := time.UnixMilli(1680000000000)
+	ctx := context.Background()
+
+	source := &fakeSource{}
+	l := newTestListener(source)
+	sub := l.hub.SubscribeAll()
+	defer sub.Close()
+
+	// deliver handles the message, failing to publish its event on every attempt so it's dead-lettered, then
+	// returns the event it published when it was redelivered from the dead-letter queue with the given time
+	deliver := func(msg Message, messageId string, redeliveredAt time.Time) *trackerpb.PlayerLocationChangedMessage {
+		source.publishErr = errors.New("broker unavailable")
+		d := &fakeDelivery{msg: msg, messageId: messageId, timestamp: at}
+		l.handle(job{delivery: d, msg: msg})
+		assert.True(t, d.rejected)
+
+		// Watchers were sent the change when it was applied
+		assert.Len(t, sub.Events(), 1)
+		<-sub.Events()
+
+		source.publishErr = nil
+		d = &fakeDelivery{msg: msg, messageId: messageId, timestamp: redeliveredAt}
+		l.handle(job{delivery: d, msg: msg})
+		assert.True(t, d.acked)
+		assert.Empty(t, sub.Events())
+
+		return source.published[len(source.published)-1]
+	}
+
+	event := deliver(&common.PlayerConnectMessage{PlayerId: playerId.String(), PlayerUsername: "Expectational",
+		ServerId: "proxy-sdgwsd-235eax"}, "1", at)
+	assert.True(t, event.Replayed)
+	assert.Equal(t, "proxy-sdgwsd-235eax", event.Current.GetProxyId())
+
+	event = deliver(&common.PlayerSwitchServerMessage{PlayerId: playerId.String(), ServerId: "lobby-z24523-sdhbsd"},
+		"2", at)
+	assert.True(t, event.Replayed)
+	assert.Nil(t, event.Previous)
+	assert.Equal(t, "lobby-z24523-sdhbsd", event.Current.GetServerId())
+
+	// The redelivery has a different time, as happens when the publisher didn't set one
+	disconnect := &common.PlayerDisconnectMessage{PlayerId: playerId.String()}
+	event = deliver(disconnect, "3", at.Add(5*time.Second))
+	assert.True(t, event.Replayed)
+	assert.Equal(t, "Expectational", event.Username)
+
+	sessions, err := l.sessions.GetPlayerSessions(ctx, playerId, at.Add(time.Hour), 10)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Len(t, sessions[0].ServerHops, 1)
+		assert.NotNil(t, sessions[0].DisconnectedAt)
+	}
+
+	// A different disconnect for the offline player is stale
+	_, err = l.handlePlayerDisconnect(ctx, disconnect, at, "4")
+	assert.ErrorIs(t, err, errStaleEvent)
+}
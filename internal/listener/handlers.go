@@ -0,0 +1,201 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/emortalmc/proto-specs/gen/go/message/common"
+	"github.com/google/uuid"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/repository"
+	"player-tracker/internal/repository/model"
+	"player-tracker/internal/watch"
+	"time"
+)
+
+// The types of the built-in messages, the full names of their proto messages
+const (
+	ConnectType    = "emortal.message.PlayerConnectMessage"
+	DisconnectType = "emortal.message.PlayerDisconnectMessage"
+	SwitchType     = "emortal.message.PlayerSwitchServerMessage"
+)
+
+// The types of the tracker's own messages, see Purger, handleProxyHeartbeat and handleProxySnapshot
+var (
+	ProxyShutdownType  = messageType(&trackerpb.ProxyShutdownMessage{})
+	ServerShutdownType = messageType(&trackerpb.ServerShutdownMessage{})
+	ProxyHeartbeatType = messageType(&trackerpb.ProxyHeartbeatMessage{})
+	ProxySnapshotType  = messageType(&trackerpb.ProxySnapshotMessage{})
+)
+
+// registerHandlers registers the handlers of the built-in messages.
+// A new message type is added by registering its decoder and handler here.
+func (l *playerListener) registerHandlers() {
+	l.registry.Register(ConnectType,
+		ProtoDecoder(func() Message { return &common.PlayerConnectMessage{} }),
+		func(ctx context.Context, msg Message, at time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+			return l.handlePlayerConnect(ctx, msg.(*common.PlayerConnectMessage), at, messageIdFrom(ctx))
+		})
+	l.registry.Register(DisconnectType,
+		ProtoDecoder(func() Message { return &common.PlayerDisconnectMessage{} }),
+		func(ctx context.Context, msg Message, at time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+			return l.handlePlayerDisconnect(ctx, msg.(*common.PlayerDisconnectMessage), at, messageIdFrom(ctx))
+		})
+	l.registry.Register(SwitchType,
+		ProtoDecoder(func() Message { return &common.PlayerSwitchServerMessage{} }),
+		func(ctx context.Context, msg Message, at time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+			return l.handlePlayerSwitch(ctx, msg.(*common.PlayerSwitchServerMessage), at, messageIdFrom(ctx))
+		})
+	l.registry.Register(ProxyShutdownType,
+		ProtoDecoder(func() Message { return &trackerpb.ProxyShutdownMessage{} }),
+		func(ctx context.Context, msg Message, at time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+			_, err := l.PurgeProxy(ctx, msg.(*trackerpb.ProxyShutdownMessage).ProxyId, at)
+			return nil, err
+		})
+	l.registry.Register(ServerShutdownType,
+		ProtoDecoder(func() Message { return &trackerpb.ServerShutdownMessage{} }),
+		func(ctx context.Context, msg Message, at time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+			_, err := l.PurgeGameServer(ctx, msg.(*trackerpb.ServerShutdownMessage).ServerId, at)
+			return nil, err
+		})
+	l.registry.Register(ProxyHeartbeatType,
+		ProtoDecoder(func() Message { return &trackerpb.ProxyHeartbeatMessage{} }),
+		func(ctx context.Context, msg Message, at time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+			return l.handleProxyHeartbeat(ctx, msg.(*trackerpb.ProxyHeartbeatMessage), at)
+		})
+	l.registry.Register(ProxySnapshotType,
+		ProtoDecoder(func() Message { return &trackerpb.ProxySnapshotMessage{} }),
+		func(ctx context.Context, msg Message, at time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+			return l.handleProxySnapshot(ctx, msg.(*trackerpb.ProxySnapshotMessage), at)
+		})
+}
+
+// handlePlayerConnect applies a connect from the message with the given ID.
+// The session writes are idempotent, so they are repeated when the message is replayed in case they failed.
+func (l *playerListener) handlePlayerConnect(ctx context.Context, msg *common.PlayerConnectMessage,
+	at time.Time, messageId string) (*trackerpb.PlayerLocationChangedMessage, error) {
+	pId, err := parsePlayerId(msg.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, player, err := l.writePlayer(ctx, pId, at, func(version int64) (*model.Player, error) {
+		return l.repo.SetPlayerProxy(ctx, pId, msg.PlayerUsername, msg.ServerId, at, messageId, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+	event := newLocationChanged(trackerpb.PlayerLocationChangedMessage_CONNECTED, pId, player.Username,
+		previous, player, at, messageId)
+	// Watchers were already sent a replayed event when it was first applied
+	if !event.Replayed {
+		l.hub.Publish(watch.PlayerEvent{
+			Type:         watch.EventConnect,
+			PlayerId:     pId,
+			Username:     player.Username,
+			GameServerId: player.GameServerId,
+			ProxyId:      player.ProxyId,
+			At:           at,
+		})
+	}
+
+	err = l.sessions.StartSession(ctx, pId, msg.PlayerUsername, msg.ServerId, at)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (l *playerListener) handlePlayerDisconnect(ctx context.Context, msg *common.PlayerDisconnectMessage,
+	at time.Time, messageId string) (*trackerpb.PlayerLocationChangedMessage, error) {
+	pId, err := parsePlayerId(msg.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+
+	player, err := l.disconnectPlayer(ctx, pId, at, messageId)
+	if errors.Is(err, repository.ErrNotFound) {
+		return l.replayPlayerDisconnect(ctx, pId, at, messageId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	l.hub.Publish(watch.PlayerEvent{Type: watch.EventDisconnect, PlayerId: pId, At: at})
+
+	err = l.sessions.EndSession(ctx, pId, at)
+	if err != nil {
+		return nil, err
+	}
+	return newLocationChanged(trackerpb.PlayerLocationChangedMessage_DISCONNECTED, pId, player.Username,
+		player, nil, at, messageId), nil
+}
+
+// replayPlayerDisconnect handles a disconnect for a player that is already offline.
+// If the message is the disconnect that was applied, it is being retried after the write, so the rest of it is
+// finished and its event is replayed. Otherwise another disconnect has been applied and it's stale.
+func (l *playerListener) replayPlayerDisconnect(ctx context.Context, playerId uuid.UUID,
+	at time.Time, messageId string) (*trackerpb.PlayerLocationChangedMessage, error) {
+	lastSeen, err := l.repo.GetPlayerLastSeen(ctx, playerId)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errStaleEvent
+	}
+	if err != nil {
+		return nil, err
+	}
+	if messageId == "" || lastSeen.LastMessageId != messageId {
+		return nil, errStaleEvent
+	}
+
+	err = l.sessions.EndSession(ctx, playerId, at)
+	if err != nil {
+		return nil, err
+	}
+
+	event := newLocationChanged(trackerpb.PlayerLocationChangedMessage_DISCONNECTED, playerId, lastSeen.Username,
+		nil, nil, at, messageId)
+	event.Replayed = true
+	return event, nil
+}
+
+// handlePlayerSwitch applies a switch from the message with the given ID, see handlePlayerConnect
+func (l *playerListener) handlePlayerSwitch(ctx context.Context, msg *common.PlayerSwitchServerMessage,
+	at time.Time, messageId string) (*trackerpb.PlayerLocationChangedMessage, error) {
+	pId, err := parsePlayerId(msg.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, player, err := l.writePlayer(ctx, pId, at, func(version int64) (*model.Player, error) {
+		return l.repo.SetPlayerGameServer(ctx, pId, msg.ServerId, at, messageId, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+	event := newLocationChanged(trackerpb.PlayerLocationChangedMessage_SWITCHED_SERVER, pId, player.Username,
+		previous, player, at, messageId)
+	if !event.Replayed {
+		l.hub.Publish(watch.PlayerEvent{
+			Type:         watch.EventSwitch,
+			PlayerId:     pId,
+			Username:     player.Username,
+			GameServerId: player.GameServerId,
+			ProxyId:      player.ProxyId,
+			At:           at,
+		})
+	}
+
+	err = l.sessions.AddSessionHop(ctx, pId, msg.ServerId, at)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// parsePlayerId parses the player ID of a message, wrapping ErrInvalidMessage if it's invalid as it never will be
+func parsePlayerId(playerId string) (uuid.UUID, error) {
+	id, err := uuid.Parse(playerId)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("%w: player id %q: %w", ErrInvalidMessage, playerId, err)
+	}
+	return id, nil
+}
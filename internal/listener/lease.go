@@ -0,0 +1,88 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"player-tracker/gen/trackerpb"
+	"time"
+)
+
+// handleProxyHeartbeat renews the proxy's lease.
+// It is renewed from when the heartbeat is handled rather than sent, so heartbeats that were queued while the
+// tracker was behind, or down, still keep the proxy alive.
+func (l *playerListener) handleProxyHeartbeat(ctx context.Context, msg *trackerpb.ProxyHeartbeatMessage,
+	_ time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+	if msg.ProxyId == "" {
+		return nil, fmt.Errorf("%w: heartbeat has no proxy id", ErrInvalidMessage)
+	}
+
+	return nil, l.repo.RenewProxyLease(ctx, msg.ProxyId, time.Now().Add(l.proxyLease))
+}
+
+// expireLeases expires proxy leases every interval until the listener is stopping.
+// The first check is a lease after starting, so every proxy has had time to send a heartbeat that is handled by
+// this tracker, or one of the others.
+func (l *playerListener) expireLeases(interval time.Duration) {
+	defer l.workersDone.Done()
+
+	select {
+	case <-l.stopping:
+		return
+	case <-time.After(l.proxyLease):
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		l.expireProxyLeases(context.Background(), time.Now())
+
+		select {
+		case <-l.stopping:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// expireProxyLeases disconnects the players of every proxy whose lease expired before now.
+// Each lease is deleted before its players are, so if several trackers find it only one expires it.
+// If disconnecting them fails the lease is restored, unless it was renewed, so it's expired again on the next check.
+func (l *playerListener) expireProxyLeases(ctx context.Context, now time.Time) {
+	leases, err := l.repo.GetExpiredProxyLeases(ctx, now)
+	if err != nil {
+		l.logger.Errorw("error getting expired proxy leases", "error", err)
+		return
+	}
+
+	for _, lease := range leases {
+		deleted, err := l.repo.DeleteProxyLease(ctx, lease)
+		if err != nil {
+			l.logger.Errorw("error deleting expired proxy lease", "proxyId", lease.ProxyId, "error", err)
+			continue
+		}
+		if !deleted {
+			// Renewed since it was read, or expired by another tracker
+			continue
+		}
+
+		// Players with an event since the lease expired are evidence the proxy is still there, so they are kept
+		players, err := l.PurgeProxy(ctx, lease.ProxyId, lease.ExpiresAt)
+		if err != nil {
+			l.logger.Errorw("error expiring proxy players", "proxyId", lease.ProxyId, "error", err)
+			if err := l.repo.RenewProxyLease(ctx, lease.ProxyId, lease.ExpiresAt); err != nil {
+				l.logger.Errorw("error restoring proxy lease", "proxyId", lease.ProxyId, "error", err)
+			}
+			continue
+		}
+
+		proxyLeasesExpiredTotal.Inc()
+		if len(players) == 0 {
+			// Usually the proxy shut down cleanly and its players were already disconnected
+			l.logger.Infow("proxy lease expired", "proxyId", lease.ProxyId, "expiredAt", lease.ExpiresAt)
+			continue
+		}
+		l.logger.Warnw("proxy lease expired, disconnected its players", "proxyId", lease.ProxyId,
+			"expiredAt", lease.ExpiresAt, "players", len(players))
+	}
+}
@@ -0,0 +1,80 @@
+package listener
+
+import (
+	"context"
+	"github.com/emortalmc/proto-specs/gen/go/message/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestListener_ProxyHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	l := newTestListener(&fakeSource{})
+
+	// Renewed from when it's handled, however old the heartbeat is
+	d := &fakeDelivery{msg: &trackerpb.ProxyHeartbeatMessage{ProxyId: "proxy-sdgwsd-235eax"}, messageId: "1",
+		timestamp: time.UnixMilli(1680000000000)}
+	l.handle(job{delivery: d, msg: d.msg})
+	assert.True(t, d.acked)
+
+	expired, err := l.repo.GetExpiredProxyLeases(ctx, time.Now().Add(l.proxyLease/2))
+	assert.NoError(t, err)
+	assert.Empty(t, expired)
+
+	expired, err = l.repo.GetExpiredProxyLeases(ctx, time.Now().Add(2*l.proxyLease))
+	assert.NoError(t, err)
+	if assert.Len(t, expired, 1) {
+		assert.Equal(t, "proxy-sdgwsd-235eax", expired[0].ProxyId)
+	}
+
+	invalid := &fakeDelivery{msg: &trackerpb.ProxyHeartbeatMessage{}, messageId: "2"}
+	l.handle(job{delivery: invalid, msg: invalid.msg})
+	assert.True(t, invalid.rejected)
+}
+
+func TestListener_ExpireProxyLeases(t *testing.T) {
+	ctx := context.Background()
+	start := time.UnixMilli(1680000000000)
+	source := &fakeSource{}
+	l := newTestListener(source)
+
+	playerIds := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	connect := func(playerId uuid.UUID, proxyId string, at time.Time) {
+		_, err := l.handlePlayerConnect(ctx, &common.PlayerConnectMessage{PlayerId: playerId.String(),
+			PlayerUsername: "Expectational", ServerId: proxyId}, at, "")
+		assert.NoError(t, err)
+	}
+	connect(playerIds[0], "proxy-sdgwsd-235eax", start)
+	// Connected after the lease expired, so the proxy was still there
+	connect(playerIds[1], "proxy-sdgwsd-235eax", start.Add(20*time.Second))
+	connect(playerIds[2], "proxy-hsdjrn-2ndjd2", start)
+
+	assert.NoError(t, l.repo.RenewProxyLease(ctx, "proxy-sdgwsd-235eax", start.Add(10*time.Second)))
+	assert.NoError(t, l.repo.RenewProxyLease(ctx, "proxy-hsdjrn-2ndjd2", start.Add(2*time.Minute)))
+
+	l.expireProxyLeases(ctx, start.Add(time.Minute))
+
+	_, err := l.repo.GetPlayer(ctx, playerIds[0])
+	assert.Equal(t, repository.ErrNotFound, err)
+	lastSeen, err := l.repo.GetPlayerLastSeen(ctx, playerIds[0])
+	assert.NoError(t, err)
+	assert.True(t, start.Add(10*time.Second).Equal(lastSeen.DisconnectedAt))
+
+	for _, playerId := range playerIds[1:] {
+		_, err := l.repo.GetPlayer(ctx, playerId)
+		assert.NoError(t, err)
+	}
+	if assert.Len(t, source.published, 1) {
+		assert.Equal(t, playerIds[0].String(), source.published[0].PlayerId)
+		assert.Equal(t, trackerpb.PlayerLocationChangedMessage_DISCONNECTED, source.published[0].Reason)
+	}
+
+	// The expired lease is gone, so it isn't expired again
+	expired, err := l.repo.GetExpiredProxyLeases(ctx, start.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, expired)
+}
@@ -0,0 +1,281 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/config"
+	"player-tracker/internal/repository"
+	"player-tracker/internal/watch"
+	"sync"
+	"time"
+)
+
+const (
+	SourceRabbitMQ = "rabbitmq"
+	SourceNATS     = "nats"
+)
+
+// Delivery is a message received from a Source.
+// It must be settled with exactly one of Ack or Reject, or left unsettled to be redelivered.
+type Delivery interface {
+	// Type is the message's type, the full name of its proto message, which it's decoded and handled by
+	Type() string
+	Body() []byte
+	// MessageId identifies the message so redelivered duplicates can be dropped and replays recognised.
+	// It is the same on every attempt when the message is retried, and empty only if the Source can't identify it.
+	MessageId() string
+	// Timestamp is when the message was published, or zero if it isn't known.
+	// It is only as precise as the publisher and broker allow, which may be to the second,
+	// and is the same on every attempt when the message is retried.
+	Timestamp() time.Time
+
+	// Ack acknowledges the message once it has been handled, or dropped as a duplicate or stale
+	Ack() error
+	// Reject dead-letters a message that failed on every attempt or can never be handled, or drops it if the Source
+	// has nowhere to dead-letter it
+	Reject(err error) error
+}
+
+// Source is a broker that player messages are consumed from, and the tracker's events are published to
+type Source interface {
+	// Start connects and starts passing each message to deliver, in the order they are received.
+	// messageTypes is the types that are handled, for Sources that subscribe to each type.
+	// An error is returned if it can't connect, after that the Source reconnects by itself.
+	Start(messageTypes []string, deliver func(Delivery)) error
+	// Stop stops consuming, returning once deliver is no longer being called.
+	// If ctx ends first ctx's error is returned.
+	Stop(ctx context.Context) error
+	// Close closes the connection, after the messages that were delivered have been settled.
+	// Messages that weren't settled are redelivered.
+	Close() error
+
+	// Consuming returns whether messages are being consumed, it is false while reconnecting
+	Consuming() bool
+
+	// Publish publishes an event, returning once the broker has persisted it.
+	// messageId is the ID of the message that caused it, for consumers to deduplicate events with.
+	// It does nothing if the Source isn't configured to publish events.
+	Publish(ctx context.Context, messageId string, event *trackerpb.PlayerLocationChangedMessage) error
+}
+
+// Listener applies player messages from a Source to the repository until it is stopped
+type Listener interface {
+	Purger
+
+	// Stop stops consuming messages and waits for the messages being handled to finish before closing the Source.
+	// Messages that were received but not handled are left unacknowledged, so they are redelivered.
+	// If ctx ends first the Source is closed anyway and ctx's error is returned.
+	Stop(ctx context.Context) error
+
+	// Consuming returns whether the Source is consuming messages.
+	// It is false while reconnecting and once the listener is stopped.
+	Consuming() bool
+}
+
+type playerListener struct {
+	logger   *zap.SugaredLogger
+	source   Source
+	registry *Registry
+	repo     repository.Repository
+	sessions repository.SessionRepository
+	hub      *watch.Hub
+
+	// processed is the IDs of recently applied messages, so redelivered duplicates are dropped
+	processed *messageIdCache
+
+	// proxyLease is how long after a proxy's last heartbeat its players are expired
+	proxyLease time.Duration
+
+	// maxAttempts is how many times a message is handled before it is dead-lettered,
+	// with retryBackoff before the second attempt, doubling for each one after
+	maxAttempts  int
+	retryBackoff time.Duration
+
+	// prefetch is how many unacknowledged messages the Source receives at once, they are handled by the workers
+	prefetch int
+	workers  []chan job
+	// workersDone is waited on for the workers and expireLeases to finish when stopping
+	workersDone sync.WaitGroup
+
+	// stopping is closed to stop handling messages
+	stopping chan struct{}
+}
+
+// NewListener registers the message handlers, starts the workers and lease expiry, and starts the source,
+// which is closed when the Listener is stopped
+func NewListener(logger *zap.SugaredLogger, cfg *config.ListenerConfig, source Source, repo repository.Repository,
+	sessions repository.SessionRepository, hub *watch.Hub) (Listener, error) {
+	registry, err := NewRegistry(cfg.UnknownTypes)
+	if err != nil {
+		return nil, err
+	}
+	registry.Use(RecoveryMiddleware(logger), MetricsMiddleware(), LoggingMiddleware(logger))
+
+	listener := &playerListener{
+		logger:   logger,
+		source:   source,
+		registry: registry,
+		repo:     repo,
+		sessions: sessions,
+		hub:      hub,
+
+		prefetch:   cfg.Prefetch,
+		processed:  newMessageIdCache(cfg.DedupeCapacity),
+		proxyLease: cfg.ProxyLease,
+
+		maxAttempts:  cfg.MaxAttempts,
+		retryBackoff: cfg.RetryBackoff,
+
+		stopping: make(chan struct{}),
+	}
+	listener.registerHandlers()
+	listener.startWorkers(cfg.Workers)
+	if cfg.LeaseCheckInterval > 0 {
+		listener.workersDone.Add(1)
+		go listener.expireLeases(cfg.LeaseCheckInterval)
+	}
+
+	err = source.Start(registry.Types(), listener.dispatch)
+	if err != nil {
+		close(listener.stopping)
+		listener.workersDone.Wait()
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+func (l *playerListener) Stop(ctx context.Context) error {
+	close(l.stopping)
+	err := l.source.Stop(ctx)
+
+	workersDone := make(chan struct{})
+	go func() {
+		l.workersDone.Wait()
+		close(workersDone)
+	}()
+
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+
+	if closeErr := l.source.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (l *playerListener) Consuming() bool {
+	select {
+	case <-l.stopping:
+		return false
+	default:
+	}
+
+	return l.source.Consuming()
+}
+
+// handle applies a decoded message, then acks it, retrying it in place after a backoff if it fails.
+// The worker waits for the retries, so the player's later messages aren't handled before it, see startWorkers.
+// Once it has failed on every attempt it is dead-lettered, as are invalid messages without being retried. If the listener stops while it's waiting to be retried,
+// it is left unacknowledged to be redelivered.
+func (l *playerListener) handle(j job) {
+	d := j.delivery
+	if l.processed.Contains(d.MessageId()) {
+		l.drop(d, OutcomeDuplicate)
+		return
+	}
+
+	// When the publisher didn't set it, when it was received is the closest time to the event
+	at := d.Timestamp()
+	if at.IsZero() {
+		at = time.Now()
+	}
+	// The repositories store times to the millisecond, so events are ordered to the millisecond everywhere
+	at = at.Truncate(time.Millisecond)
+
+	for attempt := 1; ; attempt++ {
+		err := l.apply(d, j.msg, at)
+		if errors.Is(err, errStaleEvent) {
+			l.processed.Add(d.MessageId())
+			l.drop(d, OutcomeStale)
+			return
+		}
+		if errors.Is(err, ErrInvalidMessage) {
+			l.reject(d, OutcomeInvalid, err)
+			return
+		}
+		if err == nil {
+			break
+		}
+
+		if attempt >= l.maxAttempts {
+			l.reject(d, OutcomeDeadLettered, err)
+			return
+		}
+
+		l.record(d, OutcomeRetried)
+		backoff := RetryBackoff(l.retryBackoff, attempt)
+		l.logger.Warnw("failed to handle message, retrying", "type", d.Type(), "messageId", d.MessageId(),
+			"attempts", attempt, "backoff", backoff, "error", err)
+
+		select {
+		case <-time.After(backoff):
+		case <-l.stopping:
+			return
+		}
+	}
+
+	l.processed.Add(d.MessageId())
+	l.record(d, OutcomeSuccess)
+	if err := d.Ack(); err != nil {
+		l.logger.Errorw("error acknowledging message", "type", d.Type(), "messageId", d.MessageId(), "error", err)
+	}
+}
+
+// apply handles the message and publishes its event
+func (l *playerListener) apply(d Delivery, msg Message, at time.Time) error {
+	event, err := l.registry.handle(withMessageId(context.Background(), d.MessageId()), d.Type(), msg, at)
+	if err != nil || event == nil {
+		return err
+	}
+
+	// The message is only acked once the event is persisted, otherwise it's retried and the event is
+	// published again, marked as replayed
+	err = l.publishEvent(d.MessageId(), event)
+	if err != nil {
+		l.logger.Errorw("error publishing event", "type", d.Type(), "messageId", d.MessageId(), "error", err)
+	}
+	return err
+}
+
+// drop acks a message without applying it, as it is a duplicate or older than the player's state,
+// or its type isn't registered and the registry ignores unknown types
+func (l *playerListener) drop(d Delivery, outcome string) {
+	l.record(d, outcome)
+	l.logger.Infow("dropping message", "type", d.Type(), "messageId", d.MessageId(), "outcome", outcome)
+
+	if err := d.Ack(); err != nil {
+		l.logger.Errorw("error acknowledging message", "type", d.Type(), "messageId", d.MessageId(), "error", err)
+	}
+}
+
+// reject rejects a message that failed on every attempt or can never be handled
+func (l *playerListener) reject(d Delivery, outcome string, err error) {
+	l.record(d, outcome)
+	l.logger.Errorw("rejecting message", "type", d.Type(), "messageId", d.MessageId(), "outcome", outcome, "error", err)
+
+	if err := d.Reject(err); err != nil {
+		l.logger.Errorw("error rejecting message", "type", d.Type(), "messageId", d.MessageId(), "error", err)
+	}
+}
+
+func (l *playerListener) record(d Delivery, outcome string) {
+	messagesTotal.WithLabelValues(l.registry.label(d.Type()), outcome).Inc()
+}
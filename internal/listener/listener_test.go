@@ -0,0 +1,219 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"github.com/emortalmc/proto-specs/gen/go/message/common"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/config"
+	"player-tracker/internal/repository"
+	"player-tracker/internal/watch"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSource records the events published to it, messages are delivered by calling dispatch or handle directly
+type fakeSource struct {
+	lock      sync.Mutex
+	published []*trackerpb.PlayerLocationChangedMessage
+	// publishErr is returned by Publish instead of publishing while it's set, or for the next failPublishes calls
+	publishErr    error
+	failPublishes int
+	attempts      int
+}
+
+func (s *fakeSource) Start(_ []string, _ func(Delivery)) error {
+	return nil
+}
+
+func (s *fakeSource) Stop(_ context.Context) error {
+	return nil
+}
+
+func (s *fakeSource) Close() error {
+	return nil
+}
+
+func (s *fakeSource) Consuming() bool {
+	return true
+}
+
+func (s *fakeSource) Publish(_ context.Context, _ string, event *trackerpb.PlayerLocationChangedMessage) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.attempts++
+	if s.failPublishes > 0 {
+		s.failPublishes--
+		return errors.New("broker unavailable")
+	}
+	if s.publishErr != nil {
+		return s.publishErr
+	}
+	s.published = append(s.published, event)
+	return nil
+}
+
+// fakeDelivery records how it was settled, calling settled.Done when it is if settled isn't nil.
+// Its type and body are msg's unless msgType or body are set.
+type fakeDelivery struct {
+	msg       Message
+	msgType   string
+	body      []byte
+	messageId string
+	timestamp time.Time
+
+	acked    bool
+	rejected bool
+	settled  *sync.WaitGroup
+}
+
+func (d *fakeDelivery) Type() string {
+	if d.msgType != "" {
+		return d.msgType
+	}
+	return messageType(d.msg)
+}
+
+func (d *fakeDelivery) Body() []byte {
+	if d.body != nil {
+		return d.body
+	}
+	body, _ := proto.Marshal(d.msg)
+	return body
+}
+
+func (d *fakeDelivery) MessageId() string {
+	return d.messageId
+}
+
+func (d *fakeDelivery) Timestamp() time.Time {
+	return d.timestamp
+}
+
+func (d *fakeDelivery) Ack() error {
+	d.acked = true
+	if d.settled != nil {
+		d.settled.Done()
+	}
+	return nil
+}
+
+func (d *fakeDelivery) Reject(_ error) error {
+	d.rejected = true
+	if d.settled != nil {
+		d.settled.Done()
+	}
+	return nil
+}
+
+func newTestListener(source Source) *playerListener {
+	registry, _ := NewRegistry(UnknownTypeReject)
+	l := &playerListener{
+		logger:     zap.NewNop().Sugar(),
+		source:     source,
+		registry:   registry,
+		repo:       repository.NewMemoryRepository(),
+		sessions:   repository.NewMemorySessionRepository(),
+		hub:        watch.NewHub(),
+		processed:  newMessageIdCache(100),
+		proxyLease: time.Minute,
+
+		maxAttempts:  3,
+		retryBackoff: time.Millisecond,
+
+		stopping: make(chan struct{}),
+	}
+	l.registerHandlers()
+	return l
+}
+
+func TestListener_Stop(t *testing.T) {
+	l, err := NewListener(zap.NewNop().Sugar(), &config.ListenerConfig{Prefetch: 10, Workers: 4, DedupeCapacity: 100,
+		UnknownTypes: UnknownTypeReject, ProxyLease: time.Minute, LeaseCheckInterval: time.Second},
+		&fakeSource{}, repository.NewMemoryRepository(), repository.NewMemorySessionRepository(), watch.NewHub())
+	assert.NoError(t, err)
+	assert.True(t, l.Consuming())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, l.Stop(ctx))
+	assert.False(t, l.Consuming())
+}
+
+// A message is only acked once its event is published, so an event that fails is published when it's retried
+func TestListener_PublishesEvents(t *testing.T) {
+	source := &fakeSource{failPublishes: 1}
+	l := newTestListener(source)
+
+	playerId := uuid.New()
+	d := &fakeDelivery{msg: &common.PlayerConnectMessage{PlayerId: playerId.String(), PlayerUsername: "Expectational",
+		ServerId: "proxy-sdgwsd-235eax"}, messageId: "1", timestamp: time.UnixMilli(1680000000000)}
+
+	l.handle(job{delivery: d, msg: d.msg})
+	assert.True(t, d.acked)
+	assert.Equal(t, 2, source.attempts)
+
+	assert.Len(t, source.published, 1)
+	event := source.published[0]
+	assert.Equal(t, trackerpb.PlayerLocationChangedMessage_CONNECTED, event.Reason)
+	assert.Equal(t, "proxy-sdgwsd-235eax", event.Current.GetProxyId())
+	// The player was written on the first attempt
+	assert.True(t, event.Replayed)
+}
+
+// A message that fails on every attempt is dead-lettered
+func TestListener_DeadLetters(t *testing.T) {
+	source := &fakeSource{publishErr: errors.New("not confirmed")}
+	l := newTestListener(source)
+
+	d := &fakeDelivery{msg: &common.PlayerConnectMessage{PlayerId: uuid.NewString(), PlayerUsername: "Expectational",
+		ServerId: "proxy-sdgwsd-235eax"}, messageId: "1", timestamp: time.UnixMilli(1680000000000)}
+	l.handle(job{delivery: d, msg: d.msg})
+
+	assert.True(t, d.rejected)
+	assert.False(t, d.acked)
+	assert.Equal(t, l.maxAttempts, source.attempts)
+	assert.Empty(t, source.published)
+}
+
+// A message with an invalid player ID can never be handled, so it's dead-lettered without being retried
+func TestListener_InvalidPlayerId(t *testing.T) {
+	l := newTestListener(&fakeSource{})
+	retried := testutil.ToFloat64(messagesTotal.WithLabelValues("connect", OutcomeRetried))
+
+	d := &fakeDelivery{msg: &common.PlayerConnectMessage{PlayerId: "Expectational", PlayerUsername: "Expectational",
+		ServerId: "proxy-sdgwsd-235eax"}, messageId: "1"}
+	l.handle(job{delivery: d, msg: d.msg})
+
+	assert.True(t, d.rejected)
+	assert.Equal(t, retried, testutil.ToFloat64(messagesTotal.WithLabelValues("connect", OutcomeRetried)))
+}
+
+// A message waiting to be retried when the listener stops is left unsettled, so it's redelivered
+func TestListener_StopWhileRetrying(t *testing.T) {
+	source := &fakeSource{failPublishes: 1}
+	l := newTestListener(source)
+	l.retryBackoff = time.Hour
+
+	d := &fakeDelivery{msg: &common.PlayerConnectMessage{PlayerId: uuid.NewString(), PlayerUsername: "Expectational",
+		ServerId: "proxy-sdgwsd-235eax"}, messageId: "1", timestamp: time.UnixMilli(1680000000000)}
+
+	handled := make(chan struct{})
+	go func() {
+		l.handle(job{delivery: d, msg: d.msg})
+		close(handled)
+	}()
+
+	close(l.stopping)
+	<-handled
+	assert.False(t, d.acked)
+	assert.False(t, d.rejected)
+}
@@ -0,0 +1,119 @@
+package listener
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// The outcomes of a message, counted by messagesTotal
+const (
+	OutcomeSuccess = "success"
+	// OutcomeDuplicate is a message with the ID of one that was already applied, it is acked without applying it
+	OutcomeDuplicate = "duplicate"
+	// OutcomeStale is a message older than the player's last applied event, it is acked without applying it
+	OutcomeStale = "stale"
+	// OutcomeRetried is counted each time a message fails to be handled and will be retried after a backoff
+	OutcomeRetried = "retried"
+	// OutcomeDeadLettered is a message that failed on every attempt, it is rejected so is dead-lettered if possible
+	OutcomeDeadLettered = "dead_lettered"
+	// OutcomeInvalid is a message that can never be handled, see ErrInvalidMessage. It is rejected like OutcomeDeadLettered.
+	OutcomeInvalid = "invalid"
+	// OutcomeUnknownType is a message of a type that isn't registered, it is rejected like OutcomeInvalid
+	OutcomeUnknownType = "unknown_type"
+	// OutcomeIgnored is a message of a type that isn't registered, acked without handling it as configured
+	OutcomeIgnored = "ignored"
+)
+
+// messageTypeLabels keeps the type label short for the built-in types, see Registry.label
+var messageTypeLabels = map[string]string{
+	ConnectType:    "connect",
+	DisconnectType: "disconnect",
+	SwitchType:     "switch",
+
+	ProxyShutdownType:  "proxy_shutdown",
+	ServerShutdownType: "server_shutdown",
+	ProxyHeartbeatType: "proxy_heartbeat",
+	ProxySnapshotType:  "proxy_snapshot",
+}
+
+var messagesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "player_tracker",
+	Subsystem: "listener",
+	Name:      "messages_total",
+	Help:      "Number of messages received by the listener, by message type and outcome.",
+}, []string{"type", "outcome"})
+
+var handleDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "player_tracker",
+	Subsystem: "listener",
+	Name:      "handle_duration_seconds",
+	Help:      "How long messages take to be handled, by message type.",
+}, []string{"type"})
+
+var purgedPlayersTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "player_tracker",
+	Subsystem: "listener",
+	Name:      "purged_players_total",
+	Help:      "Number of players removed because their proxy or game server went, by server type.",
+}, []string{"server"})
+
+var proxyLeasesExpiredTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "player_tracker",
+	Subsystem: "listener",
+	Name:      "proxy_leases_expired_total",
+	Help:      "Number of proxies whose players were disconnected because they stopped sending heartbeats.",
+})
+
+var snapshotCorrectionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "player_tracker",
+	Subsystem: "listener",
+	Name:      "snapshot_corrections_total",
+	Help:      "Number of players corrected to match proxy snapshots, as their events were lost, by correction.",
+}, []string{"correction"})
+
+var connectedGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "player_tracker",
+	Subsystem: "listener",
+	Name:      "connected",
+	Help:      "Whether the listener is connected to its source and consuming, 1 if it is and 0 if it's reconnecting.",
+})
+
+var reconnectsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "player_tracker",
+	Subsystem: "listener",
+	Name:      "reconnects_total",
+	Help:      "Number of attempts to reconnect to the source after losing the connection, by outcome.",
+}, []string{"outcome"})
+
+var eventsPublishedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "player_tracker",
+	Subsystem: "listener",
+	Name:      "events_published_total",
+	Help:      "Number of PlayerLocationChangedMessages published, by outcome. Failed events are retried with their message.",
+}, []string{"outcome"})
+
+// SetConnected records whether a Source is connected and consuming
+func SetConnected(connected bool) {
+	if connected {
+		connectedGauge.Set(1)
+	} else {
+		connectedGauge.Set(0)
+	}
+}
+
+// RecordReconnect records an attempt by a Source to reconnect, err is nil if it succeeded
+func RecordReconnect(err error) {
+	reconnectsTotal.WithLabelValues(outcomeLabel(err)).Inc()
+}
+
+// RecordPublish records an event a Source published, err is nil if it was persisted
+func RecordPublish(err error) {
+	eventsPublishedTotal.WithLabelValues(outcomeLabel(err)).Inc()
+}
+
+func outcomeLabel(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
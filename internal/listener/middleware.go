@@ -0,0 +1,61 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"player-tracker/gen/trackerpb"
+	"runtime/debug"
+	"time"
+)
+
+// RecoveryMiddleware turns a panic in a handler into an error, so the message is retried and eventually
+// dead-lettered rather than crashing the tracker
+func RecoveryMiddleware(logger *zap.SugaredLogger) Middleware {
+	return func(messageType string, next Handler) Handler {
+		return func(ctx context.Context, msg Message, at time.Time) (event *trackerpb.PlayerLocationChangedMessage, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Errorw("panic handling message", "type", messageType, "panic", r, "stack", string(debug.Stack()))
+					err = fmt.Errorf("panic handling %s: %v", messageType, r)
+				}
+			}()
+
+			return next(ctx, msg, at)
+		}
+	}
+}
+
+// MetricsMiddleware records how long each message takes to handle
+func MetricsMiddleware() Middleware {
+	return func(messageType string, next Handler) Handler {
+		observer := handleDuration.WithLabelValues(registeredLabel(messageType))
+
+		return func(ctx context.Context, msg Message, at time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+			start := time.Now()
+			defer func() {
+				observer.Observe(time.Since(start).Seconds())
+			}()
+
+			return next(ctx, msg, at)
+		}
+	}
+}
+
+// LoggingMiddleware logs each message at debug level once it's handled, with how long it took.
+// Failures are logged when the message is retried.
+func LoggingMiddleware(logger *zap.SugaredLogger) Middleware {
+	return func(messageType string, next Handler) Handler {
+		return func(ctx context.Context, msg Message, at time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+			start := time.Now()
+			event, err := next(ctx, msg, at)
+
+			if err == nil || errors.Is(err, errStaleEvent) {
+				logger.Debugw("handled message", "type", messageType, "at", at, "duration", time.Since(start),
+					"stale", err != nil)
+			}
+			return event, err
+		}
+	}
+}
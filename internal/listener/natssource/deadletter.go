@@ -0,0 +1,54 @@
+package natssource
+
+import (
+	"context"
+	"github.com/nats-io/nats.go"
+	"strconv"
+	"time"
+)
+
+const (
+	// attemptsHeader is how many times a dead-lettered message was delivered
+	attemptsHeader = "Player-Tracker-Attempts"
+	// errorHeader is the error of the last attempt of a dead-lettered message
+	errorHeader = "Player-Tracker-Error"
+)
+
+// deadLetter publishes a copy of a message that can't be handled to the dead-letter subject, then terminates it so
+// it isn't redelivered. If publishing fails it is nacked to be redelivered, so it isn't lost, and the error is returned.
+// If there is no dead-letter subject, it is terminated and dropped.
+func (s *source) deadLetter(d *delivery, cause error) error {
+	if s.cfg.DeadLetterSubject == "" {
+		s.logger.Errorw("failed to handle message, dropping it", "type", d.messageType, "messageId", d.MessageId(),
+			"attempts", d.attempts, "error", cause)
+		return d.jsMsg.Term()
+	}
+
+	header := nats.Header{}
+	for key, values := range d.jsMsg.Headers() {
+		header[key] = values
+	}
+	header.Set(attemptsHeader, strconv.Itoa(d.attempts))
+	header.Set(errorHeader, cause.Error())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := s.js.PublishMsg(ctx, &nats.Msg{
+		Subject: s.cfg.DeadLetterSubject + "." + d.messageType,
+		Header:  header,
+		Data:    d.jsMsg.Data(),
+	})
+	if err != nil {
+		s.logger.Errorw("error publishing failed message, redelivering it", "type", d.messageType,
+			"messageId", d.MessageId(), "attempts", d.attempts, "error", err, "handleError", cause)
+		if err := d.jsMsg.Nak(); err != nil {
+			s.logger.Errorw("error nacking message", "type", d.messageType, "messageId", d.MessageId(), "error", err)
+		}
+		return err
+	}
+
+	s.logger.Errorw("failed to handle message, dead-lettered it", "type", d.messageType, "messageId", d.MessageId(),
+		"attempts", d.attempts, "error", cause)
+	return d.jsMsg.Term()
+}
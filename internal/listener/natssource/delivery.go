@@ -0,0 +1,64 @@
+package natssource
+
+import (
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"time"
+)
+
+type delivery struct {
+	source      *source
+	jsMsg       jetstream.Msg
+	messageType string
+
+	// messageId is the ID the publisher set, or the message's stream sequence if it didn't set one
+	messageId string
+	// timestamp is when the message was stored in the stream, and attempts is how many times it has been
+	// delivered including this time. They are zero if the metadata couldn't be parsed.
+	timestamp time.Time
+	attempts  int
+}
+
+func newDelivery(s *source, jsMsg jetstream.Msg) *delivery {
+	d := &delivery{source: s, jsMsg: jsMsg, messageType: s.messageType(jsMsg.Subject()),
+		messageId: jsMsg.Headers().Get(nats.MsgIdHdr)}
+
+	if metadata, err := jsMsg.Metadata(); err == nil {
+		d.timestamp = metadata.Timestamp
+		d.attempts = int(metadata.NumDelivered)
+
+		// Retries are redeliveries of the same stream message, so its sequence identifies every attempt
+		if d.messageId == "" {
+			d.messageId = fmt.Sprintf("%s:%d", metadata.Stream, metadata.Sequence.Stream)
+		}
+	}
+	return d
+}
+
+func (d *delivery) Type() string {
+	return d.messageType
+}
+
+func (d *delivery) Body() []byte {
+	return d.jsMsg.Data()
+}
+
+// MessageId is the ID the publisher set for JetStream to deduplicate the message with,
+// or the stream and sequence of the message if the publisher didn't set one
+func (d *delivery) MessageId() string {
+	return d.messageId
+}
+
+func (d *delivery) Timestamp() time.Time {
+	return d.timestamp
+}
+
+func (d *delivery) Ack() error {
+	return d.jsMsg.Ack()
+}
+
+// Reject dead-letters the message, or drops it if there is no dead-letter subject, see deadLetter
+func (d *delivery) Reject(err error) error {
+	return d.source.deadLetter(d, err)
+}
@@ -0,0 +1,32 @@
+package natssource
+
+import (
+	"context"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"google.golang.org/protobuf/proto"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/listener"
+)
+
+// Publish publishes the event to {EventsSubject}.{type}, waiting for JetStream to acknowledge it.
+// messageId is set as the JetStream message ID, so an event that is replayed shortly after is stored once.
+// It does nothing if there is no events subject.
+func (s *source) Publish(ctx context.Context, messageId string, event *trackerpb.PlayerLocationChangedMessage) error {
+	if s.cfg.EventsSubject == "" {
+		return nil
+	}
+
+	body, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.js.PublishMsg(ctx, &nats.Msg{
+		Subject: s.cfg.EventsSubject + "." + listener.LocationChangedType,
+		Data:    body,
+	}, jetstream.WithMsgID(messageId))
+
+	listener.RecordPublish(err)
+	return err
+}
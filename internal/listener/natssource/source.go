@@ -0,0 +1,219 @@
+package natssource
+
+import (
+	"context"
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+	"player-tracker/internal/config"
+	"player-tracker/internal/listener"
+	"strings"
+	"sync"
+	"time"
+)
+
+type source struct {
+	logger *zap.SugaredLogger
+	cfg    *config.NATSConfig
+
+	conn *nats.Conn
+	js   jetstream.JetStream
+
+	// lock guards consumeCtx and consuming
+	lock       sync.Mutex
+	consumeCtx jetstream.ConsumeContext
+	consuming  bool
+
+	// ackWait is how long a delivered message can be unacknowledged before it's redelivered, see newAckWait
+	ackWait time.Duration
+
+	// prefetch is how many unacknowledged messages are pulled at once
+	prefetch int
+
+	// receiving is read locked while a message is being received, so Stop can wait for it to be delivered
+	receiving sync.RWMutex
+	// stopping is closed to stop delivering messages
+	stopping chan struct{}
+}
+
+// New creates a Source that consumes player messages from a NATS JetStream stream with a durable consumer.
+// Messages that failed on every attempt are dead-lettered to a subject, see deadLetter.
+// The NATS client reconnects by itself if the connection is lost.
+func New(logger *zap.SugaredLogger, cfg *config.NATSConfig, listenerCfg *config.ListenerConfig) listener.Source {
+	return &source{
+		logger: logger,
+		cfg:    cfg,
+
+		ackWait:  newAckWait(listenerCfg),
+		prefetch: listenerCfg.Prefetch,
+
+		stopping: make(chan struct{}),
+	}
+}
+
+// Start consumes every message type published to the stream's subject, the listener handles unknown types
+func (s *source) Start(_ []string, deliver func(listener.Delivery)) error {
+	conn, err := nats.Connect(s.cfg.URL,
+		nats.Name("player-tracker"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			listener.SetConnected(false)
+			s.logger.Errorw("nats connection lost, reconnecting", "error", err)
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			listener.SetConnected(true)
+			listener.RecordReconnect(nil)
+			s.logger.Infow("reconnected to nats", "url", conn.ConnectedUrl())
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+
+	s.js, err = jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	consumer, err := s.declare(ctx)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+		s.receive(msg, deliver)
+	}, jetstream.PullMaxMessages(s.prefetch), jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+		s.logger.Errorw("error consuming from nats", "stream", s.cfg.Stream, "consumer", s.cfg.Consumer, "error", err)
+	}))
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	s.lock.Lock()
+	s.consumeCtx = consumeCtx
+	s.consuming = true
+	s.lock.Unlock()
+	listener.SetConnected(true)
+
+	s.logger.Infow("listening for messages", "url", s.cfg.URL, "stream", s.cfg.Stream, "consumer", s.cfg.Consumer)
+	return nil
+}
+
+// declare creates the streams if they don't exist, and creates or updates the consumer
+func (s *source) declare(ctx context.Context) (jetstream.Consumer, error) {
+	err := s.declareStream(ctx, s.cfg.Stream, []string{s.cfg.Subject + ".>"})
+	if err != nil {
+		return nil, err
+	}
+
+	// Publishing to JetStream is only acknowledged if a stream stores the subject
+	var trackerSubjects []string
+	for _, subject := range []string{s.cfg.EventsSubject, s.cfg.DeadLetterSubject} {
+		if subject != "" {
+			trackerSubjects = append(trackerSubjects, subject+".>")
+		}
+	}
+	if len(trackerSubjects) > 0 {
+		err = s.declareStream(ctx, s.cfg.TrackerStream, trackerSubjects)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.js.CreateOrUpdateConsumer(ctx, s.cfg.Stream, jetstream.ConsumerConfig{
+		Durable:       s.cfg.Consumer,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		FilterSubject: s.cfg.Subject + ".>",
+		AckWait:       s.ackWait,
+		MaxAckPending: s.prefetch,
+	})
+}
+
+// newAckWait returns how long the listener can take to settle a message. Failed messages are retried by the
+// listener while they are still unacknowledged, so it must cover the backoffs between every attempt, otherwise
+// JetStream would redeliver a message while it is waiting to be retried. A minute is left for the attempts themselves.
+func newAckWait(listenerCfg *config.ListenerConfig) time.Duration {
+	ackWait := time.Minute
+	for attempts := 1; attempts < listenerCfg.MaxAttempts; attempts++ {
+		ackWait += listener.RetryBackoff(listenerCfg.RetryBackoff, attempts)
+	}
+	return ackWait
+}
+
+// declareStream creates the stream if it doesn't exist.
+// An existing stream is left as it is, as it may have been set up with other subjects or limits.
+func (s *source) declareStream(ctx context.Context, name string, subjects []string) error {
+	_, err := s.js.Stream(ctx, name)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		_, err = s.js.CreateStream(ctx, jetstream.StreamConfig{Name: name, Subjects: subjects})
+	}
+	return err
+}
+
+func (s *source) Stop(ctx context.Context) error {
+	close(s.stopping)
+
+	s.lock.Lock()
+	if s.consumeCtx != nil {
+		s.consumeCtx.Stop()
+	}
+	s.consuming = false
+	s.lock.Unlock()
+
+	// Once the write lock is held, nothing is being received and stopping stops anything after
+	received := make(chan struct{})
+	go func() {
+		s.receiving.Lock()
+		defer s.receiving.Unlock()
+		close(received)
+	}()
+
+	select {
+	case <-received:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *source) Close() error {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	return nil
+}
+
+func (s *source) Consuming() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.consuming && s.conn.IsConnected()
+}
+
+// receive delivers the message unless the source is stopping
+func (s *source) receive(msg jetstream.Msg, deliver func(listener.Delivery)) {
+	s.receiving.RLock()
+	defer s.receiving.RUnlock()
+
+	select {
+	case <-s.stopping:
+		// Left unacknowledged, so it's redelivered once the ack wait has passed
+		return
+	default:
+	}
+
+	deliver(newDelivery(s, msg))
+}
+
+// messageType returns the type of a message from its subject, {Subject}.{type}
+func (s *source) messageType(subject string) string {
+	return strings.TrimPrefix(subject, s.cfg.Subject+".")
+}
@@ -0,0 +1,200 @@
+package natssource
+
+import (
+	"context"
+	"errors"
+	"github.com/emortalmc/proto-specs/gen/go/message/common"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/config"
+	"player-tracker/internal/listener"
+	"testing"
+	"time"
+)
+
+// fakeMsg is a received JetStream message that records how it was acknowledged
+type fakeMsg struct {
+	jetstream.Msg
+
+	subject      string
+	header       nats.Header
+	data         []byte
+	numDelivered uint64
+
+	acked      bool
+	nacked     bool
+	terminated bool
+}
+
+func (m *fakeMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{Stream: "PLAYERS", Sequence: jetstream.SequencePair{Stream: 42},
+		NumDelivered: m.numDelivered, Timestamp: time.UnixMilli(1680000000000)}, nil
+}
+
+func (m *fakeMsg) Data() []byte {
+	return m.data
+}
+
+func (m *fakeMsg) Headers() nats.Header {
+	return m.header
+}
+
+func (m *fakeMsg) Subject() string {
+	return m.subject
+}
+
+func (m *fakeMsg) Ack() error {
+	m.acked = true
+	return nil
+}
+
+func (m *fakeMsg) Nak() error {
+	m.nacked = true
+	return nil
+}
+
+func (m *fakeMsg) Term() error {
+	m.terminated = true
+	return nil
+}
+
+// fakeJetStream records published messages, failing to publish while publishErr is set
+type fakeJetStream struct {
+	jetstream.JetStream
+
+	published  []*nats.Msg
+	publishErr error
+}
+
+func (js *fakeJetStream) PublishMsg(_ context.Context, msg *nats.Msg, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if js.publishErr != nil {
+		return nil, js.publishErr
+	}
+
+	js.published = append(js.published, msg)
+	return &jetstream.PubAck{}, nil
+}
+
+func newTestSource(js jetstream.JetStream, deadLetterSubject string) *source {
+	s := New(zap.NewNop().Sugar(), &config.NATSConfig{
+		Subject:           "mc.proxy",
+		EventsSubject:     "player-tracker.events",
+		DeadLetterSubject: deadLetterSubject,
+	}, &config.ListenerConfig{MaxAttempts: 3, RetryBackoff: time.Second}).(*source)
+	s.js = js
+	return s
+}
+
+func TestSource_Receive(t *testing.T) {
+	playerId := uuid.NewString()
+	body, err := proto.Marshal(&common.PlayerConnectMessage{PlayerId: playerId, PlayerUsername: "Expectational",
+		ServerId: "proxy-sdgwsd-235eax"})
+	assert.NoError(t, err)
+
+	js := &fakeJetStream{}
+	s := newTestSource(js, "player-tracker.dead-letter")
+
+	var delivered []listener.Delivery
+	deliver := func(d listener.Delivery) {
+		delivered = append(delivered, d)
+	}
+
+	msg := &fakeMsg{subject: "mc.proxy." + listener.ConnectType, header: nats.Header{nats.MsgIdHdr: {"1"}},
+		data: body, numDelivered: 1}
+	s.receive(msg, deliver)
+
+	assert.Len(t, delivered, 1)
+	assert.Equal(t, listener.ConnectType, delivered[0].Type())
+	assert.Equal(t, body, delivered[0].Body())
+	assert.Equal(t, "1", delivered[0].MessageId())
+	assert.Equal(t, time.UnixMilli(1680000000000), delivered[0].Timestamp())
+
+	// A rejected message is dead-lettered without being redelivered
+	assert.NoError(t, delivered[0].Reject(listener.ErrUnknownType))
+	assert.True(t, msg.terminated)
+	assert.Len(t, js.published, 1)
+	assert.Equal(t, "player-tracker.dead-letter."+listener.ConnectType, js.published[0].Subject)
+	assert.Equal(t, listener.ErrUnknownType.Error(), js.published[0].Header.Get(errorHeader))
+
+	// Without an ID from the publisher, the message is identified by its stream sequence, the same on every delivery
+	s.receive(&fakeMsg{subject: "mc.proxy." + listener.ConnectType, data: body, numDelivered: 2}, deliver)
+	assert.Len(t, delivered, 2)
+	assert.Equal(t, "PLAYERS:42", delivered[1].MessageId())
+}
+
+func TestSource_DeadLetter(t *testing.T) {
+	tests := []struct {
+		name              string
+		deadLetterSubject string
+		publishErr        error
+
+		wantErr        bool
+		wantNacked     bool
+		wantTerminated bool
+		wantPublished  bool
+	}{
+		{name: "dead_lettered", deadLetterSubject: "player-tracker.dead-letter", wantTerminated: true,
+			wantPublished: true},
+		{name: "publish_fails", deadLetterSubject: "player-tracker.dead-letter",
+			publishErr: errors.New("no responders"), wantErr: true, wantNacked: true},
+		{name: "no_dead_letter_subject", wantTerminated: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			js := &fakeJetStream{publishErr: test.publishErr}
+			s := newTestSource(js, test.deadLetterSubject)
+
+			msg := &fakeMsg{subject: "mc.proxy." + listener.SwitchType, data: []byte("body"), numDelivered: 2}
+			err := newDelivery(s, msg).Reject(errors.New("handling failed"))
+
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.False(t, msg.acked)
+			assert.Equal(t, test.wantNacked, msg.nacked)
+			assert.Equal(t, test.wantTerminated, msg.terminated)
+
+			if !test.wantPublished {
+				assert.Empty(t, js.published)
+				return
+			}
+			assert.Len(t, js.published, 1)
+			dead := js.published[0]
+			assert.Equal(t, "player-tracker.dead-letter."+listener.SwitchType, dead.Subject)
+			assert.Equal(t, []byte("body"), dead.Data)
+			assert.Equal(t, "2", dead.Header.Get(attemptsHeader))
+			assert.Equal(t, "handling failed", dead.Header.Get(errorHeader))
+		})
+	}
+}
+
+func TestNewAckWait(t *testing.T) {
+	// Waits for the retries after 1s, 2s and 4s
+	ackWait := newAckWait(&config.ListenerConfig{MaxAttempts: 4, RetryBackoff: time.Second})
+	assert.Equal(t, time.Minute+7*time.Second, ackWait)
+}
+
+func TestSource_Publish(t *testing.T) {
+	js := &fakeJetStream{}
+	s := newTestSource(js, "")
+
+	event := &trackerpb.PlayerLocationChangedMessage{PlayerId: uuid.NewString(),
+		Reason: trackerpb.PlayerLocationChangedMessage_DISCONNECTED}
+	assert.NoError(t, s.Publish(context.Background(), "1", event))
+
+	assert.Len(t, js.published, 1)
+	assert.Equal(t, "player-tracker.events."+listener.LocationChangedType, js.published[0].Subject)
+
+	published := &trackerpb.PlayerLocationChangedMessage{}
+	assert.NoError(t, proto.Unmarshal(js.published[0].Data, published))
+	assert.True(t, proto.Equal(event, published))
+
+	// Without an events subject nothing is published
+	s.cfg.EventsSubject = ""
+	assert.NoError(t, s.Publish(context.Background(), "2", event))
+	assert.Len(t, js.published, 1)
+}
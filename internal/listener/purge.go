@@ -0,0 +1,86 @@
+package listener
+
+import (
+	"context"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/repository/model"
+	"player-tracker/internal/watch"
+	"time"
+)
+
+// Purger removes the players of servers that have gone without sending their disconnects.
+// Once the players are purged they can't be found to purge again, so everything after the repository write is
+// best effort, failures are logged rather than returned as retrying would do nothing.
+type Purger interface {
+	// PurgeProxy disconnects every player on the proxy whose last event was not after at, ending their sessions
+	// and publishing their events. Returns the players as they were before they were disconnected.
+	PurgeProxy(ctx context.Context, proxyId string, at time.Time) ([]*model.Player, error)
+	// PurgeGameServer removes every player on the game server whose last event was not after at from it,
+	// publishing their events. Returns the players as they were before they were removed.
+	PurgeGameServer(ctx context.Context, serverId string, at time.Time) ([]*model.Player, error)
+}
+
+func (l *playerListener) PurgeProxy(ctx context.Context, proxyId string, at time.Time) ([]*model.Player, error) {
+	players, err := l.repo.DisconnectProxyPlayers(ctx, proxyId, at)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, player := range players {
+		l.hub.Publish(watch.PlayerEvent{Type: watch.EventDisconnect, PlayerId: player.Id, At: at})
+
+		err := l.sessions.EndSession(ctx, player.Id, at)
+		if err != nil {
+			l.logger.Errorw("error ending session of purged player", "playerId", player.Id, "proxyId", proxyId,
+				"error", err)
+		}
+
+		l.publishOwnEvent(newPurgedEvent(trackerpb.PlayerLocationChangedMessage_DISCONNECTED, player, nil, at))
+	}
+
+	purgedPlayersTotal.WithLabelValues("proxy").Add(float64(len(players)))
+	l.logger.Infow("purged proxy", "proxyId", proxyId, "at", at, "players", len(players))
+	return players, nil
+}
+
+func (l *playerListener) PurgeGameServer(ctx context.Context, serverId string, at time.Time) ([]*model.Player, error) {
+	players, err := l.repo.ClearServerPlayers(ctx, serverId, at)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, player := range players {
+		current := *player
+		current.GameServerId = ""
+		current.GameServer = model.ServerID{}
+
+		l.hub.Publish(watch.PlayerEvent{
+			Type:     watch.EventSwitch,
+			PlayerId: player.Id,
+			Username: player.Username,
+			ProxyId:  player.ProxyId,
+			At:       at,
+		})
+		l.publishOwnEvent(newPurgedEvent(trackerpb.PlayerLocationChangedMessage_SWITCHED_SERVER, player, &current, at))
+	}
+
+	purgedPlayersTotal.WithLabelValues("game_server").Add(float64(len(players)))
+	l.logger.Infow("purged game server", "serverId", serverId, "at", at, "players", len(players))
+	return players, nil
+}
+
+// newPurgedEvent creates the event for a purged player.
+// A purge is never replayed, so the event has no message ID and previous is always set.
+func newPurgedEvent(reason trackerpb.PlayerLocationChangedMessage_Reason, previous *model.Player,
+	current *model.Player, at time.Time) *trackerpb.PlayerLocationChangedMessage {
+	return newLocationChanged(reason, previous.Id, previous.Username, previous, current, at, "")
+}
+
+// publishOwnEvent publishes an event with its own message ID, for messages like purges that cause many events.
+// Failures are logged, as the message can't be retried to publish them again.
+func (l *playerListener) publishOwnEvent(event *trackerpb.PlayerLocationChangedMessage) {
+	err := l.publishEvent("", event)
+	if err != nil {
+		l.logger.Errorw("error publishing event", "playerId", event.PlayerId, "reason", event.Reason, "error", err)
+	}
+}
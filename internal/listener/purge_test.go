@@ -0,0 +1,114 @@
+package listener
+
+import (
+	"context"
+	"github.com/emortalmc/proto-specs/gen/go/message/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/repository"
+	"player-tracker/internal/watch"
+	"testing"
+	"time"
+)
+
+func TestListener_Purge(t *testing.T) {
+	ctx := context.Background()
+	start := time.UnixMilli(1680000000000)
+	playerIds := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	// seed connects the players to their proxies and sends them to the lobby, the third after the purges
+	seed := func(t *testing.T, l *playerListener) {
+		proxyIds := []string{"proxy-sdgwsd-235eax", "proxy-sdgwsd-235eax", "proxy-hsdjrn-2ndjd2"}
+		for i, playerId := range playerIds {
+			_, err := l.handlePlayerConnect(ctx, &common.PlayerConnectMessage{PlayerId: playerId.String(),
+				PlayerUsername: "Expectational", ServerId: proxyIds[i]}, start, "")
+			assert.NoError(t, err)
+
+			at := start.Add(time.Second)
+			if i == 2 {
+				at = start.Add(time.Minute)
+			}
+			_, err = l.handlePlayerSwitch(ctx, &common.PlayerSwitchServerMessage{PlayerId: playerId.String(),
+				ServerId: "lobby-z24523-sdhbsd"}, at, "")
+			assert.NoError(t, err)
+		}
+	}
+
+	t.Run("proxy_shutdown", func(t *testing.T) {
+		source := &fakeSource{}
+		l := newTestListener(source)
+		seed(t, l)
+
+		sub := l.hub.SubscribeAll()
+		defer sub.Close()
+
+		d := &fakeDelivery{msg: &trackerpb.ProxyShutdownMessage{ProxyId: "proxy-sdgwsd-235eax"}, messageId: "1",
+			timestamp: start.Add(2 * time.Second)}
+		l.handle(job{delivery: d, msg: d.msg})
+		assert.True(t, d.acked)
+
+		for _, playerId := range playerIds[:2] {
+			_, err := l.repo.GetPlayer(ctx, playerId)
+			assert.Equal(t, repository.ErrNotFound, err)
+
+			sessions, err := l.sessions.GetPlayerSessions(ctx, playerId, start.Add(time.Hour), 1)
+			assert.NoError(t, err)
+			if assert.Len(t, sessions, 1) && assert.NotNil(t, sessions[0].DisconnectedAt) {
+				assert.True(t, start.Add(2*time.Second).Equal(*sessions[0].DisconnectedAt))
+			}
+
+			event := <-sub.Events()
+			assert.Equal(t, watch.EventDisconnect, event.Type)
+		}
+		_, err := l.repo.GetPlayer(ctx, playerIds[2])
+		assert.NoError(t, err)
+
+		assert.Len(t, source.published, 2)
+		for _, event := range source.published {
+			assert.Equal(t, trackerpb.PlayerLocationChangedMessage_DISCONNECTED, event.Reason)
+			assert.Equal(t, "proxy-sdgwsd-235eax", event.Previous.GetProxyId())
+			assert.Equal(t, "lobby-z24523-sdhbsd", event.Previous.GetServerId())
+			assert.Nil(t, event.Current)
+			assert.False(t, event.Replayed)
+		}
+	})
+
+	t.Run("server_shutdown", func(t *testing.T) {
+		source := &fakeSource{}
+		l := newTestListener(source)
+		seed(t, l)
+
+		d := &fakeDelivery{msg: &trackerpb.ServerShutdownMessage{ServerId: "lobby-z24523-sdhbsd"}, messageId: "1",
+			timestamp: start.Add(2 * time.Second)}
+		l.handle(job{delivery: d, msg: d.msg})
+		assert.True(t, d.acked)
+
+		for i, playerId := range playerIds {
+			player, err := l.repo.GetPlayer(ctx, playerId)
+			assert.NoError(t, err)
+
+			// The third player joined the lobby after the shutdown, so is left on it
+			if i == 2 {
+				assert.Equal(t, "lobby-z24523-sdhbsd", player.GameServerId)
+			} else {
+				assert.Empty(t, player.GameServerId)
+				assert.NotEmpty(t, player.ProxyId)
+			}
+		}
+
+		assert.Len(t, source.published, 2)
+		for _, event := range source.published {
+			assert.Equal(t, trackerpb.PlayerLocationChangedMessage_SWITCHED_SERVER, event.Reason)
+			assert.Equal(t, "lobby-z24523-sdhbsd", event.Previous.GetServerId())
+			assert.Empty(t, event.Current.GetServerId())
+			assert.Equal(t, "proxy-sdgwsd-235eax", event.Current.GetProxyId())
+		}
+
+		// Redelivered, there is no one left to purge
+		retried := &fakeDelivery{msg: d.msg, messageId: "2", timestamp: d.timestamp}
+		l.handle(job{delivery: retried, msg: retried.msg})
+		assert.True(t, retried.acked)
+		assert.Len(t, source.published, 2)
+	})
+}
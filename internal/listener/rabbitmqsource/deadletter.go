@@ -0,0 +1,62 @@
+package rabbitmqsource
+
+import (
+	"context"
+	"github.com/rabbitmq/amqp091-go"
+	"time"
+)
+
+const (
+	// errorHeader is the error that the message failed with, set on dead-lettered messages
+	errorHeader = "x-player-tracker-error"
+	// timestampHeader is when the message was published in unix milliseconds, set by publishers that can.
+	// Events are ordered by when they were published, which the AMQP timestamp is too coarse for.
+	timestampHeader = "x-timestamp-ms"
+)
+
+// deadLetter publishes a copy of the message with the error to the dead-letter exchange, then acks the original once
+// RabbitMQ has confirmed the copy. This is done by the tracker rather than a dead-letter argument on the queue, as
+// queues that already exist can't have their arguments changed. If there is no dead-letter exchange the message is
+// rejected and dropped. If the copy isn't confirmed the original is requeued so it isn't lost, and the error is returned.
+func (s *source) deadLetter(d amqp091.Delivery, handleErr error) error {
+	if s.topology.deadLetterExchange == "" {
+		s.logger.Errorw("failed to handle message, dropping it", "type", d.Type, "messageId", d.MessageId,
+			"error", handleErr)
+		return d.Reject(false)
+	}
+
+	headers := copyHeaders(d)
+	headers[errorHeader] = handleErr.Error()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.publish(ctx, s.topology.deadLetterExchange, "", amqp091.Publishing{
+		Headers:      headers,
+		ContentType:  d.ContentType,
+		DeliveryMode: amqp091.Persistent,
+		MessageId:    d.MessageId,
+		Timestamp:    d.Timestamp,
+		Type:         d.Type,
+		Body:         d.Body,
+	})
+	if err != nil {
+		if err := d.Nack(false, true); err != nil {
+			s.logger.Errorw("error nacking message", "type", d.Type, "messageId", d.MessageId, "error", err)
+		}
+		return err
+	}
+
+	s.logger.Errorw("failed to handle message, dead-lettered it", "type", d.Type, "messageId", d.MessageId,
+		"error", handleErr)
+	return d.Ack(false)
+}
+
+// copyHeaders returns a copy of the delivery's headers, to be modified for a copy of the message
+func copyHeaders(d amqp091.Delivery) amqp091.Table {
+	headers := amqp091.Table{}
+	for key, value := range d.Headers {
+		headers[key] = value
+	}
+	return headers
+}
@@ -0,0 +1,63 @@
+package rabbitmqsource
+
+import (
+	"github.com/google/uuid"
+	"github.com/rabbitmq/amqp091-go"
+	"time"
+)
+
+type delivery struct {
+	source   *source
+	delivery amqp091.Delivery
+}
+
+// newDelivery wraps a received message. A message without an ID or a timestamp is given them when it's received,
+// which every attempt at handling it uses, and they are kept by the copy if it's dead-lettered.
+func newDelivery(s *source, d amqp091.Delivery) *delivery {
+	if d.MessageId == "" {
+		d.MessageId = uuid.NewString()
+	}
+	if deliveryTimestamp(d).IsZero() {
+		d.Headers = copyHeaders(d)
+		d.Headers[timestampHeader] = time.Now().UnixMilli()
+	}
+	return &delivery{source: s, delivery: d}
+}
+
+func (d *delivery) Type() string {
+	return d.delivery.Type
+}
+
+func (d *delivery) Body() []byte {
+	return d.delivery.Body
+}
+
+func (d *delivery) MessageId() string {
+	return d.delivery.MessageId
+}
+
+func (d *delivery) Timestamp() time.Time {
+	return deliveryTimestamp(d.delivery)
+}
+
+func (d *delivery) Ack() error {
+	return d.delivery.Ack(false)
+}
+
+// Reject dead-letters the message, or drops it if there is no dead-letter exchange, see deadLetter
+func (d *delivery) Reject(err error) error {
+	return d.source.deadLetter(d.delivery, err)
+}
+
+// deliveryTimestamp returns when the message was published, from timestampHeader if the publisher set it,
+// as the AMQP timestamp is only precise to the second
+func deliveryTimestamp(d amqp091.Delivery) time.Time {
+	switch ms := d.Headers[timestampHeader].(type) {
+	case int64:
+		return time.UnixMilli(ms)
+	case int32:
+		return time.UnixMilli(int64(ms))
+	default:
+		return d.Timestamp
+	}
+}
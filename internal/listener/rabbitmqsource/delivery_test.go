@@ -0,0 +1,52 @@
+package rabbitmqsource
+
+import (
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDeliveryTimestamp(t *testing.T) {
+	amqpTimestamp := time.Unix(1680000000, 0)
+
+	tests := []struct {
+		name    string
+		headers amqp091.Table
+		want    time.Time
+	}{
+		{name: "no_header", headers: nil, want: amqpTimestamp},
+		{name: "int64", headers: amqp091.Table{timestampHeader: int64(1680000000123)}, want: time.UnixMilli(1680000000123)},
+		{name: "int32", headers: amqp091.Table{timestampHeader: int32(123)}, want: time.UnixMilli(123)},
+		{name: "wrong_type", headers: amqp091.Table{timestampHeader: "1680000000123"}, want: amqpTimestamp},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := deliveryTimestamp(amqp091.Delivery{Headers: test.headers, Timestamp: amqpTimestamp})
+			assert.True(t, test.want.Equal(got), "got %s", got)
+		})
+	}
+}
+
+func TestNewDelivery(t *testing.T) {
+	t.Run("kept", func(t *testing.T) {
+		headers := amqp091.Table{timestampHeader: int64(1680000000123)}
+		d := newDelivery(nil, amqp091.Delivery{MessageId: "1", Headers: headers})
+
+		assert.Equal(t, "1", d.MessageId())
+		assert.True(t, time.UnixMilli(1680000000123).Equal(d.Timestamp()))
+		assert.Len(t, headers, 1)
+	})
+
+	// The ID and time it's given are in the delivery, so a dead-lettered copy has them too
+	t.Run("given", func(t *testing.T) {
+		before := time.Now().Truncate(time.Millisecond)
+		d := newDelivery(nil, amqp091.Delivery{})
+
+		assert.NotEmpty(t, d.delivery.MessageId)
+		assert.Equal(t, d.delivery.MessageId, d.MessageId())
+		assert.False(t, d.Timestamp().Before(before))
+		assert.Contains(t, d.delivery.Headers, timestampHeader)
+	})
+}
@@ -0,0 +1,54 @@
+package rabbitmqsource
+
+import (
+	"context"
+	"errors"
+	"github.com/rabbitmq/amqp091-go"
+	"google.golang.org/protobuf/proto"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/listener"
+)
+
+var errNotConfirmed = errors.New("message was not confirmed by rabbitmq")
+
+// Publish publishes the event to the events exchange, routed by its type, and waits for RabbitMQ to confirm it.
+// It does nothing if there is no events exchange.
+func (s *source) Publish(ctx context.Context, messageId string, event *trackerpb.PlayerLocationChangedMessage) error {
+	if s.topology.eventsExchange == "" {
+		return nil
+	}
+
+	body, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	err = s.publish(ctx, s.topology.eventsExchange, listener.LocationChangedType, amqp091.Publishing{
+		ContentType:  "application/x-protobuf",
+		DeliveryMode: amqp091.Persistent,
+		MessageId:    messageId,
+		Timestamp:    event.At.AsTime(),
+		Type:         listener.LocationChangedType,
+		Body:         body,
+	})
+	listener.RecordPublish(err)
+	return err
+}
+
+// publish publishes the message on the confirm channel and waits for RabbitMQ to confirm it
+func (s *source) publish(ctx context.Context, exchange string, key string, msg amqp091.Publishing) error {
+	s.lock.Lock()
+	channel := s.pubChann
+	s.lock.Unlock()
+
+	confirmation, err := channel.PublishWithDeferredConfirmWithContext(ctx, exchange, key, false, false, msg)
+	if err != nil {
+		return err
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err == nil && !acked {
+		err = errNotConfirmed
+	}
+	return err
+}
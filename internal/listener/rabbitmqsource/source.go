@@ -0,0 +1,289 @@
+package rabbitmqsource
+
+import (
+	"context"
+	"errors"
+	"github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+	"player-tracker/internal/config"
+	"player-tracker/internal/listener"
+	"player-tracker/internal/rabbitmq"
+	"sync"
+	"time"
+)
+
+const (
+	// consumerTag identifies the consumer so it can be cancelled when stopping
+	consumerTag = "player-tracker"
+
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+type source struct {
+	logger   *zap.SugaredLogger
+	cfg      *config.RabbitMQConfig
+	topology topology
+
+	// lock guards conn, chann and pubChann, which are replaced when reconnecting
+	lock  sync.Mutex
+	conn  *amqp091.Connection
+	chann *amqp091.Channel
+	// pubChann is the channel events and dead-lettered messages are published on, in confirm mode.
+	// It is nil if there is neither an events exchange nor a dead-letter exchange.
+	pubChann  *amqp091.Channel
+	consuming bool
+
+	// prefetch is how many unacknowledged messages RabbitMQ sends at once
+	prefetch int
+
+	// stopping is closed to stop consuming, done is closed once supervise has returned
+	stopping chan struct{}
+	done     chan struct{}
+}
+
+// New creates a Source that consumes player messages from a RabbitMQ queue.
+// Messages that failed on every attempt are dead-lettered to an exchange, see deadLetter.
+// If the connection or channel is lost, it reconnects and consumes again, see supervise.
+func New(logger *zap.SugaredLogger, cfg *config.RabbitMQConfig, listenerCfg *config.ListenerConfig) listener.Source {
+	return &source{
+		logger:   logger,
+		cfg:      cfg,
+		topology: newTopology(cfg),
+
+		prefetch: listenerCfg.Prefetch,
+
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (s *source) Start(messageTypes []string, deliver func(listener.Delivery)) error {
+	s.topology.messageTypes = messageTypes
+
+	msgChan, err := s.connect()
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infow("listening for messages", "host", s.cfg.Host, "exchange", s.topology.exchange,
+		"queue", s.topology.queue)
+	// Run as goroutine as it is blocking
+	go s.supervise(msgChan, deliver)
+
+	return nil
+}
+
+func (s *source) Stop(ctx context.Context) error {
+	close(s.stopping)
+
+	s.lock.Lock()
+	if s.chann != nil {
+		err := s.chann.Cancel(consumerTag, false)
+		if err != nil {
+			s.logger.Errorw("error cancelling consumer", "error", err)
+		}
+	}
+	s.lock.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *source) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.consuming = false
+	if s.conn != nil && !s.conn.IsClosed() {
+		// Closing the connection closes the channels too
+		return s.conn.Close()
+	}
+	return nil
+}
+
+func (s *source) Consuming() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.consuming
+}
+
+// connect connects to RabbitMQ, reusing the connection if only a channel was closed, then starts consuming
+func (s *source) connect() (<-chan amqp091.Delivery, error) {
+	s.lock.Lock()
+	conn := s.conn
+	s.lock.Unlock()
+
+	if conn == nil || conn.IsClosed() {
+		var err error
+		conn, err = rabbitmq.NewConnection(s.cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	// Without a prefetch limit RabbitMQ would send the whole queue, rather than what the workers can keep up with
+	err = channel.Qos(s.prefetch, 0, false)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	err = s.topology.declare(channel)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	// Events and dead-lettered messages are published on their own channel, so waiting for confirms doesn't hold up
+	// the consumer
+	var pubChannel *amqp091.Channel
+	if s.topology.eventsExchange != "" || s.topology.deadLetterExchange != "" {
+		pubChannel, err = conn.Channel()
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		err = pubChannel.Confirm(false)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	msgChan, err := channel.Consume(s.topology.queue, consumerTag, false, false, false, false, amqp091.Table{})
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// Stop may have been called while connecting, and wouldn't have seen this channel to cancel it
+	select {
+	case <-s.stopping:
+		_ = conn.Close()
+		return nil, errors.New("source stopped while connecting")
+	default:
+	}
+
+	s.conn = conn
+	s.chann = channel
+	s.pubChann = pubChannel
+	s.consuming = true
+	listener.SetConnected(true)
+
+	return msgChan, nil
+}
+
+// supervise delivers messages until the source is stopped, reconnecting whenever a channel is closed
+func (s *source) supervise(msgChan <-chan amqp091.Delivery, deliver func(listener.Delivery)) {
+	defer close(s.done)
+
+	for {
+		s.lock.Lock()
+		channel := s.chann
+		connClosed := s.conn.NotifyClose(make(chan *amqp091.Error, 1))
+		chanClosed := channel.NotifyClose(make(chan *amqp091.Error, 1))
+		// Left nil if nothing is published, so it's never received from
+		var pubChanClosed chan *amqp091.Error
+		if s.pubChann != nil {
+			pubChanClosed = s.pubChann.NotifyClose(make(chan *amqp091.Error, 1))
+		}
+		s.lock.Unlock()
+
+		if !s.listen(msgChan, deliver, connClosed, chanClosed, pubChanClosed) {
+			return
+		}
+
+		s.lock.Lock()
+		s.consuming = false
+		// Either channel may still be open if only the other was closed, and the connection may be reused,
+		// so the old consumer is closed rather than left consuming alongside the new one
+		_ = s.chann.Close()
+		if s.pubChann != nil {
+			_ = s.pubChann.Close()
+		}
+		s.lock.Unlock()
+		listener.SetConnected(false)
+
+		var ok bool
+		msgChan, ok = s.reconnect()
+		if !ok {
+			return
+		}
+	}
+}
+
+// listen delivers messages until the source is stopped, returning false, or a channel is lost, returning true
+func (s *source) listen(msgChan <-chan amqp091.Delivery, deliver func(listener.Delivery),
+	connClosed <-chan *amqp091.Error, chanClosed <-chan *amqp091.Error, pubChanClosed <-chan *amqp091.Error) bool {
+	for {
+		select {
+		case <-s.stopping:
+			return false
+		case err := <-connClosed:
+			s.logger.Errorw("rabbitmq connection closed", "error", err)
+			return true
+		case err := <-chanClosed:
+			s.logger.Errorw("rabbitmq channel closed", "error", err)
+			return true
+		case err := <-pubChanClosed:
+			s.logger.Errorw("rabbitmq publishing channel closed", "error", err)
+			return true
+		case d, ok := <-msgChan:
+			if !ok {
+				select {
+				case <-s.stopping:
+					return false
+				default:
+				}
+				s.logger.Errorw("stopped receiving messages, the consumer was cancelled", "queue", s.topology.queue)
+				return true
+			}
+
+			deliver(newDelivery(s, d))
+		}
+	}
+}
+
+// reconnect tries to connect with exponential backoff until it succeeds, returning the new deliveries,
+// or the source is stopped, returning false
+func (s *source) reconnect() (<-chan amqp091.Delivery, bool) {
+	backoff := minReconnectBackoff
+	for attempt := 1; ; attempt++ {
+		s.logger.Warnw("reconnecting to rabbitmq", "attempt", attempt, "backoff", backoff)
+
+		select {
+		case <-s.stopping:
+			return nil, false
+		case <-time.After(backoff):
+		}
+
+		msgChan, err := s.connect()
+		listener.RecordReconnect(err)
+		if err == nil {
+			s.logger.Infow("reconnected to rabbitmq, listening for messages", "attempt", attempt, "queue", s.topology.queue)
+			return msgChan, true
+		}
+		s.logger.Errorw("failed to reconnect to rabbitmq", "attempt", attempt, "error", err)
+
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package rabbitmqsource
+
+import (
+	"github.com/rabbitmq/amqp091-go"
+	"player-tracker/internal/config"
+)
+
+// topology is the exchanges and queues the source uses, declared by the source so no manual setup is needed.
+// Declaring is idempotent, but fails if something already exists with different settings.
+type topology struct {
+	// exchange is where the player messages are published, routed by message type to queue
+	exchange     string
+	exchangeType string
+	queue        string
+	// messageTypes is the types queue is bound to exchange with
+	messageTypes []string
+
+	// deadLetterExchange receives messages that failed on every attempt or can never be handled, they are kept in
+	// deadLetterQueue. If it's empty those messages are dropped. See source.deadLetter.
+	deadLetterExchange string
+	deadLetterQueue    string
+
+	// eventsExchange is where the tracker publishes its own events, if it's empty none are published.
+	// They are kept in eventsQueue, so an event is never confirmed and dropped for having nowhere to be routed.
+	eventsExchange string
+	eventsQueue    string
+}
+
+func newTopology(cfg *config.RabbitMQConfig) topology {
+	t := topology{
+		exchange:     cfg.Exchange,
+		exchangeType: cfg.ExchangeType,
+		queue:        cfg.Queue,
+	}
+	if cfg.DeadLetterExchange != "" {
+		t.deadLetterExchange = cfg.DeadLetterExchange
+		t.deadLetterQueue = cfg.Queue + ".dead-letter"
+	}
+	if cfg.EventsExchange != "" {
+		t.eventsExchange = cfg.EventsExchange
+		t.eventsQueue = cfg.Queue + ".events"
+	}
+
+	return t
+}
+
+func (t topology) declare(channel *amqp091.Channel) error {
+	err := channel.ExchangeDeclare(t.exchange, t.exchangeType, true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	if t.deadLetterExchange != "" {
+		err = channel.ExchangeDeclare(t.deadLetterExchange, amqp091.ExchangeFanout, true, false, false, false, nil)
+		if err != nil {
+			return err
+		}
+
+		_, err = channel.QueueDeclare(t.deadLetterQueue, true, false, false, false, nil)
+		if err != nil {
+			return err
+		}
+
+		err = channel.QueueBind(t.deadLetterQueue, "", t.deadLetterExchange, false, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The queue has no arguments so it matches the one in existing environments, declaring it with different ones
+	// would fail. Messages are dead-lettered by the source publishing them to deadLetterExchange instead.
+	_, err = channel.QueueDeclare(t.queue, true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, messageType := range t.messageTypes {
+		err = channel.QueueBind(t.queue, messageType, t.exchange, false, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if t.eventsExchange != "" {
+		err = channel.ExchangeDeclare(t.eventsExchange, amqp091.ExchangeTopic, true, false, false, false, nil)
+		if err != nil {
+			return err
+		}
+
+		_, err = channel.QueueDeclare(t.eventsQueue, true, false, false, false, nil)
+		if err != nil {
+			return err
+		}
+
+		err = channel.QueueBind(t.eventsQueue, "#", t.eventsExchange, false, nil)
+	}
+	return err
+}
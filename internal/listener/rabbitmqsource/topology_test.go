@@ -0,0 +1,45 @@
+package rabbitmqsource
+
+import (
+	"github.com/stretchr/testify/assert"
+	"player-tracker/internal/config"
+	"testing"
+)
+
+func TestNewTopology(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.RabbitMQConfig
+		want topology
+	}{
+		{
+			name: "dead_letter_exchange",
+			cfg: &config.RabbitMQConfig{Exchange: "mc:proxy:all", ExchangeType: "topic", Queue: "player-tracker:all",
+				DeadLetterExchange: "player-tracker.dead-letter", EventsExchange: "player-tracker:events"},
+			want: topology{
+				exchange:           "mc:proxy:all",
+				exchangeType:       "topic",
+				queue:              "player-tracker:all",
+				deadLetterExchange: "player-tracker.dead-letter",
+				deadLetterQueue:    "player-tracker:all.dead-letter",
+				eventsExchange:     "player-tracker:events",
+				eventsQueue:        "player-tracker:all.events",
+			},
+		},
+		{
+			name: "no_dead_letter_exchange",
+			cfg:  &config.RabbitMQConfig{Exchange: "mc:proxy:all", ExchangeType: "topic", Queue: "player-tracker:all"},
+			want: topology{
+				exchange:     "mc:proxy:all",
+				exchangeType: "topic",
+				queue:        "player-tracker:all",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, newTopology(test.cfg))
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/emortalmc/proto-specs/gen/go/message/common"
+	"github.com/google/uuid"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/repository/model"
+	"time"
+)
+
+// The corrections made when reconciling a proxy snapshot, the labels of snapshotCorrectionsTotal
+const (
+	CorrectionConnected    = "connected"
+	CorrectionDisconnected = "disconnected"
+	CorrectionMoved        = "moved"
+)
+
+// handleProxySnapshot corrects the players the repository has on the proxy to match its snapshot.
+// The number of corrections of each kind is counted and logged, even if a later correction fails.
+func (l *playerListener) handleProxySnapshot(ctx context.Context, msg *trackerpb.ProxySnapshotMessage,
+	at time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+	if msg.ProxyId == "" {
+		return nil, fmt.Errorf("%w: snapshot has no proxy id", ErrInvalidMessage)
+	}
+
+	corrections := make(map[string]int)
+	err := l.reconcileProxy(ctx, msg, at, corrections)
+
+	total := 0
+	for correction, count := range corrections {
+		snapshotCorrectionsTotal.WithLabelValues(correction).Add(float64(count))
+		total += count
+	}
+	if total > 0 {
+		l.logger.Warnw("corrected proxy players from snapshot", "proxyId", msg.ProxyId, "at", at,
+			"connected", corrections[CorrectionConnected], "disconnected", corrections[CorrectionDisconnected],
+			"moved", corrections[CorrectionMoved])
+	}
+
+	return nil, err
+}
+
+// reconcileProxy applies each difference between the snapshot and the repository as the event that was lost,
+// at the time the snapshot was taken. They go through the same checks as the real events, so players with an event
+// after the snapshot are left alone, as the snapshot is out of date for them.
+// The differences are found again if the message is retried, so corrections that were applied aren't repeated.
+// The corrections have no message ID, as the snapshot's would make a connect and the switch after it a replay.
+func (l *playerListener) reconcileProxy(ctx context.Context, msg *trackerpb.ProxySnapshotMessage, at time.Time,
+	corrections map[string]int) error {
+	players, err := l.repo.GetProxyPlayers(ctx, msg.ProxyId)
+	if err != nil {
+		return err
+	}
+
+	// Players left in phantoms after going through the snapshot aren't connected to the proxy
+	phantoms := make(map[uuid.UUID]*model.Player, len(players))
+	for _, player := range players {
+		phantoms[player.Id] = player
+	}
+
+	for _, snapshotPlayer := range msg.Players {
+		playerId, err := uuid.Parse(snapshotPlayer.PlayerId)
+		if err != nil {
+			l.logger.Warnw("skipping snapshot player with invalid id", "proxyId", msg.ProxyId,
+				"playerId", snapshotPlayer.PlayerId, "error", err)
+			continue
+		}
+
+		player, ok := phantoms[playerId]
+		delete(phantoms, playerId)
+
+		if !ok {
+			connected, err := l.applyCorrection(func() (*trackerpb.PlayerLocationChangedMessage, error) {
+				return l.handlePlayerConnect(ctx, &common.PlayerConnectMessage{PlayerId: snapshotPlayer.PlayerId,
+					PlayerUsername: snapshotPlayer.Username, ServerId: msg.ProxyId}, at, "")
+			})
+			if err != nil {
+				return err
+			}
+			if !connected {
+				continue
+			}
+			corrections[CorrectionConnected]++
+
+			// Sending them to their game server is part of connecting them, so isn't counted as well
+			if snapshotPlayer.ServerId != "" {
+				_, err = l.applyCorrection(l.switchCorrection(ctx, snapshotPlayer, at))
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if snapshotPlayer.ServerId == "" || snapshotPlayer.ServerId == player.GameServerId {
+			continue
+		}
+		moved, err := l.applyCorrection(l.switchCorrection(ctx, snapshotPlayer, at))
+		if err != nil {
+			return err
+		}
+		if moved {
+			corrections[CorrectionMoved]++
+		}
+	}
+
+	for playerId := range phantoms {
+		disconnected, err := l.applyCorrection(func() (*trackerpb.PlayerLocationChangedMessage, error) {
+			return l.handlePlayerDisconnect(ctx, &common.PlayerDisconnectMessage{PlayerId: playerId.String()}, at, "")
+		})
+		if err != nil {
+			return err
+		}
+		if disconnected {
+			corrections[CorrectionDisconnected]++
+		}
+	}
+
+	return nil
+}
+
+// applyCorrection applies a correction with the handler of the event that was lost and publishes its event.
+// Returns false if the event is stale, as the player has changed since the snapshot was taken.
+func (l *playerListener) applyCorrection(
+	handle func() (*trackerpb.PlayerLocationChangedMessage, error)) (bool, error) {
+	event, err := handle()
+	if errors.Is(err, errStaleEvent) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	l.publishOwnEvent(event)
+	return true, nil
+}
+
+// switchCorrection returns a correction that sends the snapshot player to their game server
+func (l *playerListener) switchCorrection(ctx context.Context, snapshotPlayer *trackerpb.SnapshotPlayer,
+	at time.Time) func() (*trackerpb.PlayerLocationChangedMessage, error) {
+	return func() (*trackerpb.PlayerLocationChangedMessage, error) {
+		return l.handlePlayerSwitch(ctx, &common.PlayerSwitchServerMessage{PlayerId: snapshotPlayer.PlayerId,
+			ServerId: snapshotPlayer.ServerId}, at, "")
+	}
+}
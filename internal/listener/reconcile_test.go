@@ -0,0 +1,143 @@
+package listener
+
+import (
+	"context"
+	"github.com/emortalmc/proto-specs/gen/go/message/common"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestListener_ProxySnapshot(t *testing.T) {
+	ctx := context.Background()
+	start := time.UnixMilli(1680000000000)
+	snapshotAt := start.Add(2 * time.Second)
+	playerIds := []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+
+	source := &fakeSource{}
+	l := newTestListener(source)
+
+	// The first four players are on the proxy, the fourth connecting after the snapshot, and the sixth is on another
+	connect := func(playerId uuid.UUID, proxyId string, at time.Time) {
+		_, err := l.handlePlayerConnect(ctx, &common.PlayerConnectMessage{PlayerId: playerId.String(),
+			PlayerUsername: "Expectational", ServerId: proxyId}, at, "")
+		assert.NoError(t, err)
+		_, err = l.handlePlayerSwitch(ctx, &common.PlayerSwitchServerMessage{PlayerId: playerId.String(),
+			ServerId: "lobby-z24523-sdhbsd"}, at.Add(time.Second), "")
+		assert.NoError(t, err)
+	}
+	for _, playerId := range playerIds[:3] {
+		connect(playerId, "proxy-sdgwsd-235eax", start)
+	}
+	connect(playerIds[3], "proxy-sdgwsd-235eax", start.Add(time.Minute))
+	connect(playerIds[5], "proxy-hsdjrn-2ndjd2", start)
+
+	before := map[string]float64{}
+	for _, correction := range []string{CorrectionConnected, CorrectionDisconnected, CorrectionMoved} {
+		before[correction] = testutil.ToFloat64(snapshotCorrectionsTotal.WithLabelValues(correction))
+	}
+
+	d := &fakeDelivery{msg: &trackerpb.ProxySnapshotMessage{ProxyId: "proxy-sdgwsd-235eax", Players: []*trackerpb.SnapshotPlayer{
+		{PlayerId: playerIds[0].String(), Username: "Expectational", ServerId: "lobby-z24523-sdhbsd"},
+		{PlayerId: playerIds[1].String(), Username: "Expectational", ServerId: "block-sumo-2ndkfs-dfd2x"},
+		{PlayerId: playerIds[4].String(), Username: "Emortal", ServerId: "lobby-z24523-sdhbsd"},
+		{PlayerId: playerIds[5].String(), Username: "Expectational"},
+		{PlayerId: "not-a-uuid", Username: "Zak", ServerId: "lobby-z24523-sdhbsd"},
+	}}, messageId: "1", timestamp: snapshotAt}
+	l.handle(job{delivery: d, msg: d.msg})
+	assert.True(t, d.acked)
+
+	tests := []struct {
+		name       string
+		playerId   uuid.UUID
+		wantServer string
+		wantProxy  string
+	}{
+		{name: "unchanged", playerId: playerIds[0], wantServer: "lobby-z24523-sdhbsd", wantProxy: "proxy-sdgwsd-235eax"},
+		{name: "moved", playerId: playerIds[1], wantServer: "block-sumo-2ndkfs-dfd2x", wantProxy: "proxy-sdgwsd-235eax"},
+		{name: "connected_after_snapshot", playerId: playerIds[3], wantServer: "lobby-z24523-sdhbsd",
+			wantProxy: "proxy-sdgwsd-235eax"},
+		{name: "missing", playerId: playerIds[4], wantServer: "lobby-z24523-sdhbsd", wantProxy: "proxy-sdgwsd-235eax"},
+		// The snapshot has no game server for them, so the one they had is kept
+		{name: "other_proxy", playerId: playerIds[5], wantServer: "lobby-z24523-sdhbsd", wantProxy: "proxy-sdgwsd-235eax"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			player, err := l.repo.GetPlayer(ctx, tc.playerId)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.wantServer, player.GameServerId)
+				assert.Equal(t, tc.wantProxy, player.ProxyId)
+			}
+		})
+	}
+
+	_, err := l.repo.GetPlayer(ctx, playerIds[2])
+	assert.Equal(t, repository.ErrNotFound, err)
+
+	sessions, err := l.sessions.GetPlayerSessions(ctx, playerIds[4], start.Add(time.Hour), 1)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+
+	// The move, the disconnect, the missing player's connect and switch, and the other proxy's player's connect
+	assert.Len(t, source.published, 5)
+
+	after := map[string]float64{}
+	for _, correction := range []string{CorrectionConnected, CorrectionDisconnected, CorrectionMoved} {
+		after[correction] = testutil.ToFloat64(snapshotCorrectionsTotal.WithLabelValues(correction)) - before[correction]
+	}
+	assert.Equal(t, map[string]float64{CorrectionConnected: 2, CorrectionDisconnected: 1, CorrectionMoved: 1}, after)
+
+	// Redelivered, everything already matches
+	retried := &fakeDelivery{msg: d.msg, messageId: "2", timestamp: d.timestamp}
+	l.handle(job{delivery: retried, msg: retried.msg})
+	assert.True(t, retried.acked)
+	assert.Len(t, source.published, 5)
+}
+
+// racingRepository runs race before the first disconnect, as another worker can between a disconnect's read and write
+type racingRepository struct {
+	repository.Repository
+	race func()
+}
+
+func (r *racingRepository) DisconnectPlayer(ctx context.Context, playerId uuid.UUID, at time.Time, messageId string,
+	expectedVersion int64) error {
+	if race := r.race; race != nil {
+		r.race = nil
+		race()
+	}
+	return r.Repository.DisconnectPlayer(ctx, playerId, at, messageId, expectedVersion)
+}
+
+// A snapshot handled on another worker that reconnects the player isn't undone by a disconnect from before it
+func TestListener_DisconnectRacingSnapshot(t *testing.T) {
+	ctx := context.Background()
+	start := time.UnixMilli(1680000000000)
+	playerId := uuid.New()
+
+	l := newTestListener(&fakeSource{})
+	_, err := l.handlePlayerConnect(ctx, &common.PlayerConnectMessage{PlayerId: playerId.String(),
+		PlayerUsername: "Expectational", ServerId: "proxy-sdgwsd-235eax"}, start, "")
+	assert.NoError(t, err)
+
+	l.repo = &racingRepository{Repository: l.repo, race: func() {
+		_, err := l.handleProxySnapshot(ctx, &trackerpb.ProxySnapshotMessage{ProxyId: "proxy-hsdjrn-2ndjd2",
+			Players: []*trackerpb.SnapshotPlayer{{PlayerId: playerId.String(), Username: "Expectational"}}},
+			start.Add(2*time.Second))
+		assert.NoError(t, err)
+	}}
+
+	d := &fakeDelivery{msg: &common.PlayerDisconnectMessage{PlayerId: playerId.String()}, messageId: "1",
+		timestamp: start.Add(time.Second)}
+	l.handle(job{delivery: d, msg: d.msg})
+	assert.True(t, d.acked)
+
+	player, err := l.repo.GetPlayer(ctx, playerId)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "proxy-hsdjrn-2ndjd2", player.ProxyId)
+	}
+}
@@ -0,0 +1,158 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"player-tracker/gen/trackerpb"
+	"sort"
+	"time"
+)
+
+// What happens to a message of a type that isn't registered
+const (
+	// UnknownTypeReject rejects the message, so it is dead-lettered if the source has somewhere to dead-letter it
+	UnknownTypeReject = "reject"
+	// UnknownTypeAck acknowledges and ignores the message, for when the source also carries messages for others
+	UnknownTypeAck = "ack"
+)
+
+// ErrUnknownType is returned when decoding a message of a type that isn't registered
+var ErrUnknownType = errors.New("unknown message type")
+
+// ErrInvalidMessage is wrapped by the errors of messages that can never be handled, like a body that can't be
+// unmarshalled or an invalid player ID. They are rejected straight away rather than retried.
+var ErrInvalidMessage = errors.New("invalid message")
+
+// Message is a decoded message. Messages with a GetPlayerId method are handled in order for each player,
+// other messages are handled in order for each type.
+type Message interface {
+	proto.Message
+}
+
+// playerMessage is a Message about a player
+type playerMessage interface {
+	Message
+	GetPlayerId() string
+}
+
+// Decoder decodes the body of a message
+type Decoder func(body []byte) (Message, error)
+
+// Handler applies a decoded message whose event happened at the given time.
+// It returns the event to publish, or nil if the message doesn't change a player's location or publishes its own.
+// errStaleEvent is returned if the message is older than the state it would change, it is dropped rather than retried.
+// Errors wrapping ErrInvalidMessage are rejected rather than retried.
+// The context carries the ID of the message, which is the same on every delivery of it, see messageIdFrom.
+type Handler func(ctx context.Context, msg Message, at time.Time) (*trackerpb.PlayerLocationChangedMessage, error)
+
+// Middleware wraps the handler of a message type
+type Middleware func(messageType string, next Handler) Handler
+
+// ProtoDecoder returns a Decoder that unmarshals the body into a new proto message
+func ProtoDecoder(newMessage func() Message) Decoder {
+	return func(body []byte) (Message, error) {
+		msg := newMessage()
+		if err := proto.Unmarshal(body, msg); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+		}
+		return msg, nil
+	}
+}
+
+type route struct {
+	decode Decoder
+	handle Handler
+}
+
+// Registry maps message types, the full names of their proto messages, to how they are decoded and handled.
+// Every handler is wrapped in the middleware. It must not be changed once the listener has started.
+type Registry struct {
+	routes     map[string]route
+	middleware []Middleware
+
+	// unknownType is what happens to messages of types that aren't registered, UnknownTypeReject or UnknownTypeAck
+	unknownType string
+}
+
+func NewRegistry(unknownType string) (*Registry, error) {
+	if unknownType != UnknownTypeReject && unknownType != UnknownTypeAck {
+		return nil, fmt.Errorf("unknown type policy must be %q or %q, not %q", UnknownTypeReject, UnknownTypeAck,
+			unknownType)
+	}
+
+	return &Registry{routes: make(map[string]route), unknownType: unknownType}, nil
+}
+
+// Use adds middleware, which wraps the handlers of types registered after it in the order it was added,
+// so the first middleware is the outermost
+func (r *Registry) Use(middleware ...Middleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Register sets how messages of the type are decoded and handled, replacing any previous registration
+func (r *Registry) Register(messageType string, decode Decoder, handle Handler) {
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handle = r.middleware[i](messageType, handle)
+	}
+
+	r.routes[messageType] = route{decode: decode, handle: handle}
+}
+
+// Types returns the registered message types, sorted
+func (r *Registry) Types() []string {
+	types := make([]string, 0, len(r.routes))
+	for messageType := range r.routes {
+		types = append(types, messageType)
+	}
+
+	sort.Strings(types)
+	return types
+}
+
+// decode decodes a message of the given type, returning ErrUnknownType if the type isn't registered
+func (r *Registry) decode(messageType string, body []byte) (Message, error) {
+	route, ok := r.routes[messageType]
+	if !ok {
+		return nil, ErrUnknownType
+	}
+
+	return route.decode(body)
+}
+
+// handle handles a message decoded by decode
+func (r *Registry) handle(ctx context.Context, messageType string, msg Message,
+	at time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+	return r.routes[messageType].handle(ctx, msg, at)
+}
+
+type messageIdKey struct{}
+
+// withMessageId returns a copy of the context carrying the ID of the message being handled
+func withMessageId(ctx context.Context, messageId string) context.Context {
+	return context.WithValue(ctx, messageIdKey{}, messageId)
+}
+
+// messageIdFrom returns the ID of the message being handled, or an empty string if there isn't one
+func messageIdFrom(ctx context.Context) string {
+	messageId, _ := ctx.Value(messageIdKey{}).(string)
+	return messageId
+}
+
+// label returns the metrics label of a message type, labelling types that aren't registered "unknown"
+// so the label is bounded
+func (r *Registry) label(messageType string) string {
+	if _, ok := r.routes[messageType]; !ok {
+		return "unknown"
+	}
+	return registeredLabel(messageType)
+}
+
+// registeredLabel returns the metrics label of a registered message type, which is short for the built-in types
+func registeredLabel(messageType string) string {
+	if label, ok := messageTypeLabels[messageType]; ok {
+		return label
+	}
+	return messageType
+}
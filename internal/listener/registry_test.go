@@ -0,0 +1,119 @@
+package listener
+
+import (
+	"context"
+	"github.com/emortalmc/proto-specs/gen/go/message/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"player-tracker/gen/trackerpb"
+	"testing"
+	"time"
+)
+
+func TestNewRegistry(t *testing.T) {
+	_, err := NewRegistry(UnknownTypeReject)
+	assert.NoError(t, err)
+
+	_, err = NewRegistry(UnknownTypeAck)
+	assert.NoError(t, err)
+
+	_, err = NewRegistry("requeue")
+	assert.Error(t, err)
+}
+
+func TestRegistry_Decode(t *testing.T) {
+	l := newTestListener(&fakeSource{})
+	assert.Equal(t, []string{ConnectType, DisconnectType, SwitchType, ProxyHeartbeatType, ProxyShutdownType,
+		ProxySnapshotType, ServerShutdownType}, l.registry.Types())
+
+	playerId := uuid.NewString()
+	body, err := proto.Marshal(&common.PlayerSwitchServerMessage{PlayerId: playerId, ServerId: "lobby-z24523-sdhbsd"})
+	assert.NoError(t, err)
+
+	msg, err := l.registry.decode(SwitchType, body)
+	assert.NoError(t, err)
+	assert.IsType(t, &common.PlayerSwitchServerMessage{}, msg)
+	assert.Equal(t, playerId, msg.(*common.PlayerSwitchServerMessage).PlayerId)
+
+	_, err = l.registry.decode("emortal.message.UnknownMessage", body)
+	assert.ErrorIs(t, err, ErrUnknownType)
+
+	_, err = l.registry.decode(ConnectType, []byte("not a proto message"))
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+	assert.NotErrorIs(t, err, ErrUnknownType)
+
+	assert.Equal(t, "switch", l.registry.label(SwitchType))
+	assert.Equal(t, "unknown", l.registry.label("emortal.message.UnknownMessage"))
+}
+
+func TestRegistry_Middleware(t *testing.T) {
+	registry, err := NewRegistry(UnknownTypeReject)
+	assert.NoError(t, err)
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(messageType string, next Handler) Handler {
+			return func(ctx context.Context, msg Message, at time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+				calls = append(calls, name+" "+messageType)
+				return next(ctx, msg, at)
+			}
+		}
+	}
+	registry.Use(RecoveryMiddleware(zap.NewNop().Sugar()), record("first"), record("second"))
+
+	registry.Register(SwitchType, ProtoDecoder(func() Message { return &common.PlayerSwitchServerMessage{} }),
+		func(_ context.Context, _ Message, _ time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+			calls = append(calls, "handler")
+			return &trackerpb.PlayerLocationChangedMessage{}, nil
+		})
+	registry.Register(ConnectType, ProtoDecoder(func() Message { return &common.PlayerConnectMessage{} }),
+		func(_ context.Context, _ Message, _ time.Time) (*trackerpb.PlayerLocationChangedMessage, error) {
+			panic("handler panicked")
+		})
+
+	event, err := registry.handle(context.Background(), SwitchType, &common.PlayerSwitchServerMessage{}, time.Now())
+	assert.NoError(t, err)
+	assert.NotNil(t, event)
+	assert.Equal(t, []string{"first " + SwitchType, "second " + SwitchType, "handler"}, calls)
+
+	// A panic is returned as an error, so the message is retried
+	event, err = registry.handle(context.Background(), ConnectType, &common.PlayerConnectMessage{}, time.Now())
+	assert.ErrorContains(t, err, "handler panicked")
+	assert.Nil(t, event)
+}
+
+func TestListener_DispatchUnknownTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		unknownType string
+		msgType     string
+		body        []byte
+
+		wantAcked    bool
+		wantRejected bool
+	}{
+		{name: "reject_unknown", unknownType: UnknownTypeReject, msgType: "emortal.message.UnknownMessage",
+			wantRejected: true},
+		{name: "ack_unknown", unknownType: UnknownTypeAck, msgType: "emortal.message.UnknownMessage",
+			wantAcked: true},
+		{name: "invalid_body", unknownType: UnknownTypeAck, msgType: ConnectType,
+			body: []byte("not a proto message"), wantRejected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newTestListener(&fakeSource{})
+			l.registry.unknownType = test.unknownType
+
+			d := &fakeDelivery{msg: &common.PlayerConnectMessage{PlayerId: uuid.NewString()}, msgType: test.msgType,
+				body: test.body, messageId: "1"}
+			// Settled without being queued, so no workers are needed
+			l.dispatch(d)
+
+			assert.Equal(t, test.wantAcked, d.acked)
+			assert.Equal(t, test.wantRejected, d.rejected)
+		})
+	}
+}
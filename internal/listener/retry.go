@@ -0,0 +1,19 @@
+package listener
+
+import "time"
+
+const maxRetryBackoff = time.Minute
+
+// RetryBackoff returns how long to wait before retrying a message that has failed the given number of times,
+// starting at base and doubling with each attempt up to a minute
+func RetryBackoff(base time.Duration, attempts int) time.Duration {
+	backoff := base
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return backoff
+}
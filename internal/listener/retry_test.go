@@ -0,0 +1,25 @@
+package listener
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		want     time.Duration
+	}{
+		{name: "first_retry", attempts: 1, want: time.Second},
+		{name: "doubles", attempts: 3, want: 4 * time.Second},
+		{name: "capped", attempts: 10, want: maxRetryBackoff},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, RetryBackoff(time.Second, test.attempts))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"player-tracker/internal/repository"
+	"player-tracker/internal/repository/model"
+	"time"
+)
+
+// maxWriteAttempts is how many times a write is retried when another write to the player happens at the same time
+const maxWriteAttempts = 3
+
+// errStaleEvent is returned by the handlers when the message's event happened before the player's latest state,
+// e.g. a switch that arrives after the player disconnected. The message is dropped rather than retried.
+// Events at the same time as the player's latest state aren't stale, they are applied in the order they arrive,
+// as publishers that only set the AMQP timestamp send several events a second with the same time.
+var errStaleEvent = errors.New("event is older than the player's last applied event")
+
+// writePlayer applies a write from an event that happened at the given time, unless the event is stale.
+// An event is stale if it happened before the online player's last event, or before an offline player
+// disconnected. The write is passed the version it must be applied to, so the check and the write are atomic.
+// previous is the player before the write, or nil if they were offline.
+func (l *playerListener) writePlayer(ctx context.Context, playerId uuid.UUID, at time.Time,
+	write func(expectedVersion int64) (*model.Player, error)) (previous *model.Player, player *model.Player, err error) {
+	for attempt := 0; attempt < maxWriteAttempts; attempt++ {
+		previous, err = l.currentPlayer(ctx, playerId, at)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		version := int64(0)
+		if previous != nil {
+			version = previous.Version
+		}
+
+		player, err = write(version)
+		if !errors.Is(err, repository.ErrVersionConflict) {
+			return previous, player, err
+		}
+	}
+
+	return nil, nil, err
+}
+
+// disconnectPlayer disconnects the online player for an event that happened at the given time, unless it's stale.
+// The player is only deleted at the version that was read, so a write in between isn't lost, e.g. a reconnect
+// applied by a snapshot on another worker. Returns the player before they were disconnected,
+// or repository.ErrNotFound if they are offline.
+func (l *playerListener) disconnectPlayer(ctx context.Context, playerId uuid.UUID, at time.Time,
+	messageId string) (player *model.Player, err error) {
+	for attempt := 0; attempt < maxWriteAttempts; attempt++ {
+		player, err = l.repo.GetPlayer(ctx, playerId)
+		if err != nil {
+			return nil, err
+		}
+		if at.Before(player.LastEventAt) {
+			return nil, errStaleEvent
+		}
+
+		err = l.repo.DisconnectPlayer(ctx, playerId, at, messageId, player.Version)
+		if err == nil {
+			return player, nil
+		}
+		if !errors.Is(err, repository.ErrVersionConflict) {
+			return nil, err
+		}
+	}
+
+	return nil, err
+}
+
+// currentPlayer returns the online player, or nil if they are offline, or errStaleEvent if the event is stale
+func (l *playerListener) currentPlayer(ctx context.Context, playerId uuid.UUID, at time.Time) (*model.Player, error) {
+	player, err := l.repo.GetPlayer(ctx, playerId)
+	if err == nil {
+		if at.Before(player.LastEventAt) {
+			return nil, errStaleEvent
+		}
+		return player, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	lastSeen, err := l.repo.GetPlayerLastSeen(ctx, playerId)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if at.Before(lastSeen.DisconnectedAt) {
+		return nil, errStaleEvent
+	}
+	return nil, nil
+}
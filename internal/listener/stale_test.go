@@ -0,0 +1,211 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"github.com/emortalmc/proto-specs/gen/go/message/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"player-tracker/internal/repository"
+	"player-tracker/internal/repository/model"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestListener_StaleAndDuplicateEvents(t *testing.T) {
+	ctx := context.Background()
+	playerId := uuid.New()
+	start := time.UnixMilli(1680000000000)
+
+	type event struct {
+		messageId string
+		at        time.Time
+		msg       Message
+	}
+	connect := func(messageId string, at time.Duration) event {
+		return event{messageId, start.Add(at), &common.PlayerConnectMessage{PlayerId: playerId.String(),
+			PlayerUsername: "Expectational", ServerId: "proxy-sdgwsd-235eax"}}
+	}
+	switchServer := func(messageId string, at time.Duration, serverId string) event {
+		return event{messageId, start.Add(at), &common.PlayerSwitchServerMessage{PlayerId: playerId.String(), ServerId: serverId}}
+	}
+	disconnect := func(messageId string, at time.Duration) event {
+		return event{messageId, start.Add(at), &common.PlayerDisconnectMessage{PlayerId: playerId.String()}}
+	}
+
+	tests := []struct {
+		name   string
+		events []event
+		// wantApplied is whether each event was applied, the rest must have been dropped
+		wantApplied []bool
+		// wantOnline is whether the player is online at the end, and wantServerId their game server if they are
+		wantOnline   bool
+		wantServerId string
+	}{
+		{
+			name: "in_order",
+			events: []event{
+				connect("1", 0),
+				switchServer("2", time.Second, "lobby-z24523-sdhbsd"),
+				switchServer("3", 2*time.Second, "block-sumo-2ndkfs-dfd2x"),
+			},
+			wantApplied:  []bool{true, true, true},
+			wantOnline:   true,
+			wantServerId: "block-sumo-2ndkfs-dfd2x",
+		},
+		{
+			name: "late_switch",
+			events: []event{
+				connect("1", 0),
+				switchServer("3", 2*time.Second, "block-sumo-2ndkfs-dfd2x"),
+				switchServer("2", time.Second, "lobby-z24523-sdhbsd"),
+			},
+			wantApplied:  []bool{true, true, false},
+			wantOnline:   true,
+			wantServerId: "block-sumo-2ndkfs-dfd2x",
+		},
+		{
+			name: "switch_after_disconnect_does_not_recreate_player",
+			events: []event{
+				connect("1", 0),
+				disconnect("3", 2*time.Second),
+				switchServer("2", time.Second, "lobby-z24523-sdhbsd"),
+			},
+			wantApplied: []bool{true, true, false},
+		},
+		{
+			name: "late_disconnect_after_reconnect",
+			events: []event{
+				connect("3", 2*time.Second),
+				disconnect("2", time.Second),
+			},
+			wantApplied: []bool{true, false},
+			wantOnline:  true,
+		},
+		{
+			name: "reconnect_at_same_time_as_disconnect",
+			events: []event{
+				connect("1", 0),
+				disconnect("2", time.Second),
+				connect("3", time.Second),
+			},
+			wantApplied: []bool{true, true, true},
+			wantOnline:  true,
+		},
+		{
+			// Publishers that only set the AMQP timestamp send events in the same second with the same time,
+			// they are applied in the order they arrive
+			name: "same_time_in_arrival_order",
+			events: []event{
+				connect("1", 0),
+				switchServer("2", 0, "lobby-z24523-sdhbsd"),
+				switchServer("3", 0, "block-sumo-2ndkfs-dfd2x"),
+			},
+			wantApplied:  []bool{true, true, true},
+			wantOnline:   true,
+			wantServerId: "block-sumo-2ndkfs-dfd2x",
+		},
+		{
+			name: "duplicate_message_id",
+			events: []event{
+				connect("1", 0),
+				switchServer("2", time.Second, "lobby-z24523-sdhbsd"),
+				switchServer("2", time.Second, "lobby-z24523-sdhbsd"),
+			},
+			wantApplied:  []bool{true, true, false},
+			wantOnline:   true,
+			wantServerId: "lobby-z24523-sdhbsd",
+		},
+		{
+			name: "duplicate_disconnect",
+			events: []event{
+				connect("1", 0),
+				disconnect("2", time.Second),
+				disconnect("3", time.Second),
+			},
+			wantApplied: []bool{true, true, false},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newTestListener(&fakeSource{})
+
+			// Applied events are published to the hub, dropped ones aren't
+			sub := l.hub.SubscribeAll()
+			defer sub.Close()
+
+			for i, e := range test.events {
+				d := &fakeDelivery{msg: e.msg, messageId: e.messageId, timestamp: e.at}
+				l.handle(job{delivery: d, msg: d.msg})
+
+				assert.True(t, d.acked, "event %d wasn't acked", i)
+				select {
+				case <-sub.Events():
+					assert.True(t, test.wantApplied[i], "event %d was applied", i)
+				default:
+					assert.False(t, test.wantApplied[i], "event %d wasn't applied", i)
+				}
+			}
+
+			player, err := l.repo.GetPlayer(ctx, playerId)
+			if !test.wantOnline {
+				assert.Equal(t, repository.ErrNotFound, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantServerId, player.GameServerId)
+		})
+	}
+}
+
+// unavailableRepository fails to write a player's proxy the next failures times
+type unavailableRepository struct {
+	repository.Repository
+	failures int
+}
+
+func (r *unavailableRepository) SetPlayerProxy(ctx context.Context, playerId uuid.UUID, username string,
+	proxyId string, at time.Time, messageId string, expectedVersion int64) (*model.Player, error) {
+	if r.failures > 0 {
+		r.failures--
+		return nil, errors.New("repository unavailable")
+	}
+	return r.Repository.SetPlayerProxy(ctx, playerId, username, proxyId, at, messageId, expectedVersion)
+}
+
+// A message that fails is retried before the player's later messages are handled, so it isn't dropped as stale.
+// E.g. a connect that failed would be stale after the player's switch, leaving them without a proxy or username.
+func TestListener_RetryBeforeLaterEvents(t *testing.T) {
+	playerId := uuid.New()
+	start := time.UnixMilli(1680000000000)
+
+	l := newTestListener(&fakeSource{})
+	l.repo = &unavailableRepository{Repository: l.repo, failures: 2}
+	l.prefetch = 10
+	l.startWorkers(4)
+
+	var settled sync.WaitGroup
+	settled.Add(2)
+	connect := &fakeDelivery{msg: &common.PlayerConnectMessage{PlayerId: playerId.String(),
+		PlayerUsername: "Expectational", ServerId: "proxy-sdgwsd-235eax"}, messageId: "1", timestamp: start,
+		settled: &settled}
+	switchServer := &fakeDelivery{msg: &common.PlayerSwitchServerMessage{PlayerId: playerId.String(),
+		ServerId: "lobby-z24523-sdhbsd"}, messageId: "2", timestamp: start.Add(time.Second), settled: &settled}
+	l.dispatch(connect)
+	l.dispatch(switchServer)
+
+	settled.Wait()
+	close(l.stopping)
+	l.workersDone.Wait()
+	assert.True(t, connect.acked)
+	assert.True(t, switchServer.acked)
+
+	player, err := l.repo.GetPlayer(context.Background(), playerId)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "lobby-z24523-sdhbsd", player.GameServerId)
+		assert.Equal(t, "proxy-sdgwsd-235eax", player.ProxyId)
+		assert.Equal(t, "Expectational", player.Username)
+	}
+}
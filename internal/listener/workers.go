@@ -0,0 +1,93 @@
+package listener
+
+import (
+	"errors"
+	"hash/fnv"
+)
+
+// job is a decoded delivery waiting to be handled by a worker
+type job struct {
+	delivery Delivery
+	msg      Message
+}
+
+// startWorkers starts the workers that handle messages.
+// Each player's messages always go to the same worker, so they are handled in the order they were received,
+// while different players' messages are handled in parallel. Messages are acked individually as they finish.
+// A message that fails is retried by its worker, holding back the messages queued behind it, see handle.
+func (l *playerListener) startWorkers(count int) {
+	if count < 1 {
+		count = 1
+	}
+
+	l.workers = make([]chan job, count)
+	for i := range l.workers {
+		// The prefetch limits how many messages are waiting across all the workers, so this never blocks
+		// unless most of the waiting messages are for the players of one worker
+		jobs := make(chan job, l.prefetch)
+		l.workers[i] = jobs
+
+		l.workersDone.Add(1)
+		go l.work(jobs)
+	}
+}
+
+// dispatch decodes the delivery and queues it on its player's worker.
+// Messages of unknown types are rejected or dropped depending on the registry, and invalid messages are rejected.
+func (l *playerListener) dispatch(d Delivery) {
+	msg, err := l.registry.decode(d.Type(), d.Body())
+	if errors.Is(err, ErrUnknownType) && l.registry.unknownType == UnknownTypeAck {
+		l.drop(d, OutcomeIgnored)
+		return
+	}
+	if errors.Is(err, ErrUnknownType) {
+		l.reject(d, OutcomeUnknownType, err)
+		return
+	}
+	if err != nil {
+		l.reject(d, OutcomeInvalid, err)
+		return
+	}
+
+	select {
+	case l.workers[workerIndex(shardKey(d.Type(), msg), len(l.workers))] <- job{delivery: d, msg: msg}:
+	case <-l.stopping:
+		// Left unacknowledged, so it's redelivered once the source is closed
+	}
+}
+
+// work handles jobs until the listener is stopping.
+// The job channels are never closed, as the source may still be dispatching if it didn't stop in time.
+func (l *playerListener) work(jobs <-chan job) {
+	defer l.workersDone.Done()
+
+	for {
+		select {
+		case <-l.stopping:
+			return
+		case j := <-jobs:
+			select {
+			case <-l.stopping:
+				// Queued messages aren't started once stopping, they are redelivered
+				return
+			default:
+			}
+
+			l.handle(j)
+		}
+	}
+}
+
+// shardKey returns what the message is sharded between the workers by, its player or otherwise its type
+func shardKey(messageType string, msg Message) string {
+	if msg, ok := msg.(playerMessage); ok {
+		return msg.GetPlayerId()
+	}
+	return messageType
+}
+
+func workerIndex(playerId string, workers int) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(playerId))
+	return int(hash.Sum32() % uint32(workers))
+}
@@ -0,0 +1,73 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"github.com/emortalmc/proto-specs/gen/go/message/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"player-tracker/internal/repository"
+	"sync"
+	"testing"
+)
+
+func TestListener_Workers(t *testing.T) {
+	l := newTestListener(&fakeSource{})
+	l.prefetch = 10
+	l.startWorkers(4)
+
+	var settled sync.WaitGroup
+	var deliveries []*fakeDelivery
+	deliver := func(msg Message) {
+		d := &fakeDelivery{msg: msg, messageId: uuid.NewString(), settled: &settled}
+		deliveries = append(deliveries, d)
+
+		settled.Add(1)
+		l.dispatch(d)
+	}
+
+	// Each player's messages are interleaved with the others', and must still be applied in order
+	playerIds := make([]uuid.UUID, 20)
+	for i := range playerIds {
+		playerIds[i] = uuid.New()
+		deliver(&common.PlayerConnectMessage{PlayerId: playerIds[i].String(), PlayerUsername: "username",
+			ServerId: "proxy-sdgwsd-235eax"})
+	}
+	for hop := 0; hop < 5; hop++ {
+		for _, playerId := range playerIds {
+			deliver(&common.PlayerSwitchServerMessage{PlayerId: playerId.String(),
+				ServerId: fmt.Sprintf("lobby-z24523-hop%d", hop)})
+		}
+	}
+	for _, playerId := range playerIds[:10] {
+		deliver(&common.PlayerDisconnectMessage{PlayerId: playerId.String()})
+	}
+
+	settled.Wait()
+	close(l.stopping)
+	l.workersDone.Wait()
+
+	for i, playerId := range playerIds {
+		player, err := l.repo.GetPlayer(context.Background(), playerId)
+		if i < 10 {
+			assert.Equal(t, repository.ErrNotFound, err)
+			continue
+		}
+
+		assert.NoError(t, err)
+		assert.Equal(t, "lobby-z24523-hop4", player.GameServerId)
+	}
+
+	for i, d := range deliveries {
+		assert.True(t, d.acked, "delivery %d wasn't acked", i)
+	}
+}
+
+func TestWorkerIndex(t *testing.T) {
+	playerId := uuid.NewString()
+
+	index := workerIndex(playerId, 16)
+	assert.Equal(t, index, workerIndex(playerId, 16))
+	assert.GreaterOrEqual(t, index, 0)
+	assert.Less(t, index, 16)
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"player-tracker/internal/fleet"
+	"player-tracker/internal/repository"
+	"time"
+)
+
+const namespace = "player_tracker"
+
+var (
+	onlinePlayersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "online_players"),
+		"Number of players online.",
+		nil, nil,
+	)
+	fleetPlayersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "fleet_players"),
+		"Number of players on each configured fleet.",
+		[]string{"fleet"}, nil,
+	)
+	proxyPlayersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "proxy_players"),
+		"Number of players connected to each proxy.",
+		[]string{"proxy"}, nil,
+	)
+)
+
+// repositoryCollector reads the player counts from the repository on each scrape,
+// so the gauges are always consistent with what the RPCs return.
+type repositoryCollector struct {
+	repo   repository.Repository
+	fleets *fleet.Registry
+}
+
+// NewRepositoryCollector creates a collector of the online, per fleet and per proxy player counts
+func NewRepositoryCollector(repo repository.Repository, fleets *fleet.Registry) prometheus.Collector {
+	return &repositoryCollector{repo: repo, fleets: fleets}
+}
+
+func (c *repositoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- onlinePlayersDesc
+	ch <- fleetPlayersDesc
+	ch <- proxyPlayersDesc
+}
+
+func (c *repositoryCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	online, err := c.repo.PlayerCount(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(onlinePlayersDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(onlinePlayersDesc, prometheus.GaugeValue, float64(online))
+	}
+
+	fleetCounts, err := c.repo.GetFleetPlayerCounts(ctx, c.fleets.AllFleets())
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(fleetPlayersDesc, err)
+	} else {
+		for name, count := range fleetCounts {
+			ch <- prometheus.MustNewConstMetric(fleetPlayersDesc, prometheus.GaugeValue, float64(count), name)
+		}
+	}
+
+	proxyCounts, err := c.repo.GetProxyPlayerCounts(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(proxyPlayersDesc, err)
+	} else {
+		for proxyId, count := range proxyCounts {
+			ch <- prometheus.MustNewConstMetric(proxyPlayersDesc, prometheus.GaugeValue, float64(count), proxyId)
+		}
+	}
+}
+
+// NewServer creates an HTTP server of the default registry's metrics on /metrics
+func NewServer(port uint16) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: mux}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"player-tracker/internal/fleet"
+	"player-tracker/internal/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRepositoryCollector(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	fleets, err := fleet.NewRegistry(map[string][]string{"lobby": {"lobby"}, "marathon": {"marathon"}})
+	assert.NoError(t, err)
+
+	players := []struct {
+		proxyId  string
+		serverId string
+	}{
+		{"proxy-sdgwsd-235eax", "lobby-z24523-sdhbsd"},
+		{"proxy-sdgwsd-235eax", "lobby-z24523-dhsbs2"},
+		{"proxy-hsdjrn-2ndjd2", "block-sumo-sdgsdb-ndfb3n"},
+	}
+	for _, p := range players {
+		pId := uuid.New()
+		_, err := repo.SetPlayerProxy(ctx, pId, "username", p.proxyId, time.Time{}, "", repository.AnyVersion)
+		assert.NoError(t, err)
+		_, err = repo.SetPlayerGameServer(ctx, pId, p.serverId, time.Time{}, "", repository.AnyVersion)
+		assert.NoError(t, err)
+	}
+
+	// Unconfigured fleets such as block-sumo aren't exported, configured fleets without players are
+	expected := `
+# HELP player_tracker_fleet_players Number of players on each configured fleet.
+# TYPE player_tracker_fleet_players gauge
+player_tracker_fleet_players{fleet="lobby"} 2
+player_tracker_fleet_players{fleet="marathon"} 0
+# HELP player_tracker_online_players Number of players online.
+# TYPE player_tracker_online_players gauge
+player_tracker_online_players 3
+# HELP player_tracker_proxy_players Number of players connected to each proxy.
+# TYPE player_tracker_proxy_players gauge
+player_tracker_proxy_players{proxy="proxy-hsdjrn-2ndjd2"} 1
+player_tracker_proxy_players{proxy="proxy-sdgwsd-235eax"} 2
+`
+	err = testutil.CollectAndCompare(NewRepositoryCollector(repo, fleets), strings.NewReader(expected))
+	assert.NoError(t, err)
+}
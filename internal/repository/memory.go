@@ -0,0 +1,409 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"player-tracker/internal/repository/model"
+	"sync"
+	"time"
+)
+
+type memoryRepository struct {
+	Repository
+
+	lock sync.RWMutex
+
+	players  map[uuid.UUID]*model.Player
+	lastSeen map[uuid.UUID]*model.LastSeen
+	// leases is when each proxy's lease expires
+	leases map[string]time.Time
+
+	// Secondary indexes, these must be kept in sync with players
+	serverIndex map[string]map[uuid.UUID]struct{}
+	proxyIndex  map[string]map[uuid.UUID]struct{}
+	fleetIndex  map[string]map[uuid.UUID]struct{}
+}
+
+// NewMemoryRepository creates a Repository that holds all data in process memory.
+// It is safe for concurrent use, but data is lost when the process exits.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		players:     make(map[uuid.UUID]*model.Player),
+		lastSeen:    make(map[uuid.UUID]*model.LastSeen),
+		leases:      make(map[string]time.Time),
+		serverIndex: make(map[string]map[uuid.UUID]struct{}),
+		proxyIndex:  make(map[string]map[uuid.UUID]struct{}),
+		fleetIndex:  make(map[string]map[uuid.UUID]struct{}),
+	}
+}
+
+func (r *memoryRepository) Ping(_ context.Context) error {
+	return nil
+}
+
+func (r *memoryRepository) Close(_ context.Context) error {
+	return nil
+}
+
+func (r *memoryRepository) SetPlayerGameServer(_ context.Context, playerId uuid.UUID, serverId string, at time.Time, messageId string, expectedVersion int64) (*model.Player, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	player, err := r.playerForWrite(playerId, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	r.unindexGameServer(player)
+	player.GameServerId = serverId
+	player.GameServer, _ = model.ParseServerID(serverId)
+	r.indexGameServer(player)
+	player.LastEventAt = at
+	player.LastMessageId = messageId
+	player.Version++
+
+	clone := *player
+	return &clone, nil
+}
+
+func (r *memoryRepository) SetPlayerProxy(_ context.Context, playerId uuid.UUID, username string, proxyId string, at time.Time, messageId string, expectedVersion int64) (*model.Player, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	player, err := r.playerForWrite(playerId, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	removeFromIndex(r.proxyIndex, player.ProxyId, playerId)
+	player.Username = username
+	player.ProxyId = proxyId
+	addToIndex(r.proxyIndex, player.ProxyId, playerId)
+	player.LastEventAt = at
+	player.LastMessageId = messageId
+	player.Version++
+
+	clone := *player
+	return &clone, nil
+}
+
+func (r *memoryRepository) GetPlayer(_ context.Context, playerId uuid.UUID) (*model.Player, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	player, ok := r.players[playerId]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	clone := *player
+	return &clone, nil
+}
+
+func (r *memoryRepository) GetPlayers(_ context.Context, playerIds []uuid.UUID) ([]*model.Player, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var players []*model.Player
+	for _, playerId := range playerIds {
+		player, ok := r.players[playerId]
+		if !ok {
+			continue
+		}
+
+		clone := *player
+		players = append(players, &clone)
+	}
+
+	return players, nil
+}
+
+func (r *memoryRepository) DeletePlayer(_ context.Context, playerId uuid.UUID) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_, ok := r.deletePlayer(playerId)
+	if !ok {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *memoryRepository) DisconnectPlayer(_ context.Context, playerId uuid.UUID, at time.Time, messageId string, expectedVersion int64) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	player, ok := r.players[playerId]
+	if !ok {
+		return ErrNotFound
+	}
+	if expectedVersion != AnyVersion && expectedVersion != player.Version {
+		return ErrVersionConflict
+	}
+	r.deletePlayer(playerId)
+
+	r.lastSeen[playerId] = &model.LastSeen{
+		PlayerId:       playerId,
+		Username:       player.Username,
+		GameServerId:   player.GameServerId,
+		ProxyId:        player.ProxyId,
+		DisconnectedAt: at,
+		LastMessageId:  messageId,
+	}
+
+	return nil
+}
+
+func (r *memoryRepository) GetPlayerLastSeen(_ context.Context, playerId uuid.UUID) (*model.LastSeen, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	lastSeen, ok := r.lastSeen[playerId]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	clone := *lastSeen
+	return &clone, nil
+}
+
+func (r *memoryRepository) DisconnectProxyPlayers(_ context.Context, proxyId string, at time.Time) ([]*model.Player, error) {
+	testHookPurge()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var players []*model.Player
+	for playerId := range r.proxyIndex[proxyId] {
+		if r.players[playerId].LastEventAt.After(at) {
+			continue
+		}
+
+		// Deleting from the index being ranged over is safe, the deleted players just aren't visited
+		player, _ := r.deletePlayer(playerId)
+		r.lastSeen[playerId] = &model.LastSeen{
+			PlayerId:       playerId,
+			Username:       player.Username,
+			GameServerId:   player.GameServerId,
+			ProxyId:        player.ProxyId,
+			DisconnectedAt: at,
+		}
+		players = append(players, player)
+	}
+
+	return players, nil
+}
+
+func (r *memoryRepository) ClearServerPlayers(_ context.Context, serverId string, at time.Time) ([]*model.Player, error) {
+	testHookPurge()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var players []*model.Player
+	for playerId := range r.serverIndex[serverId] {
+		player := r.players[playerId]
+		if player.LastEventAt.After(at) {
+			continue
+		}
+
+		previous := *player
+		r.unindexGameServer(player)
+		player.GameServerId = ""
+		player.GameServer = model.ServerID{}
+		player.LastEventAt = at
+		player.LastMessageId = ""
+		player.Version++
+		players = append(players, &previous)
+	}
+
+	return players, nil
+}
+
+func (r *memoryRepository) RenewProxyLease(_ context.Context, proxyId string, expiresAt time.Time) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if expiresAt.After(r.leases[proxyId]) {
+		r.leases[proxyId] = expiresAt
+	}
+	return nil
+}
+
+func (r *memoryRepository) GetExpiredProxyLeases(_ context.Context, now time.Time) ([]*model.ProxyLease, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var leases []*model.ProxyLease
+	for proxyId, expiresAt := range r.leases {
+		if expiresAt.Before(now) {
+			leases = append(leases, &model.ProxyLease{ProxyId: proxyId, ExpiresAt: expiresAt})
+		}
+	}
+
+	return leases, nil
+}
+
+func (r *memoryRepository) DeleteProxyLease(_ context.Context, lease *model.ProxyLease) (bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	expiresAt, ok := r.leases[lease.ProxyId]
+	if !ok || !expiresAt.Equal(lease.ExpiresAt) {
+		return false, nil
+	}
+
+	delete(r.leases, lease.ProxyId)
+	return true, nil
+}
+
+func (r *memoryRepository) GetServerPlayers(_ context.Context, serverId string) ([]*model.Player, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.indexPlayers(r.serverIndex, serverId), nil
+}
+
+func (r *memoryRepository) GetProxyPlayers(_ context.Context, proxyId string) ([]*model.Player, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.indexPlayers(r.proxyIndex, proxyId), nil
+}
+
+func (r *memoryRepository) GetServerPlayerCount(_ context.Context, serverId model.ServerID) (int64, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if serverId.IsProxy() {
+		return int64(len(r.proxyIndex[serverId.String()])), nil
+	}
+	return int64(len(r.serverIndex[serverId.String()])), nil
+}
+
+func (r *memoryRepository) GetFleetPlayerCounts(_ context.Context, fleets []string) (map[string]int64, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	counts := make(map[string]int64, len(fleets))
+	for _, fleet := range fleets {
+		counts[fleet] = int64(len(r.fleetIndex[fleet]))
+	}
+
+	return counts, nil
+}
+
+func (r *memoryRepository) GetProxyPlayerCounts(_ context.Context) (map[string]int64, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	counts := make(map[string]int64, len(r.proxyIndex))
+	for proxyId, players := range r.proxyIndex {
+		counts[proxyId] = int64(len(players))
+	}
+
+	return counts, nil
+}
+
+func (r *memoryRepository) PlayerCount(_ context.Context) (int64, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return int64(len(r.players)), nil
+}
+
+// playerForWrite returns the player to be modified, inserting it if it doesn't exist.
+// Returns ErrVersionConflict if expectedVersion isn't AnyVersion and doesn't match the stored version.
+// The caller must hold the write lock.
+func (r *memoryRepository) playerForWrite(playerId uuid.UUID, expectedVersion int64) (*model.Player, error) {
+	player, ok := r.players[playerId]
+
+	var version int64
+	if ok {
+		version = player.Version
+	}
+	if expectedVersion != AnyVersion && expectedVersion != version {
+		return nil, ErrVersionConflict
+	}
+
+	if !ok {
+		player = &model.Player{Id: playerId}
+		r.players[playerId] = player
+	}
+	return player, nil
+}
+
+// deletePlayer removes the player and its index entries, returning false if it didn't exist.
+// The caller must hold the write lock.
+func (r *memoryRepository) deletePlayer(playerId uuid.UUID) (*model.Player, bool) {
+	player, ok := r.players[playerId]
+	if !ok {
+		return nil, false
+	}
+
+	r.unindexGameServer(player)
+	removeFromIndex(r.proxyIndex, player.ProxyId, playerId)
+	delete(r.players, playerId)
+
+	return player, true
+}
+
+// indexPlayers returns copies of the players in the index under key.
+// The caller must hold the read lock.
+func (r *memoryRepository) indexPlayers(index map[string]map[uuid.UUID]struct{}, key string) []*model.Player {
+	var players []*model.Player
+	for playerId := range index[key] {
+		clone := *r.players[playerId]
+		players = append(players, &clone)
+	}
+
+	return players
+}
+
+// indexGameServer adds the player to the server and fleet indexes for its current GameServerId and GameServer.
+// The caller must hold the write lock.
+func (r *memoryRepository) indexGameServer(player *model.Player) {
+	if player.GameServerId == "" {
+		return
+	}
+
+	addToIndex(r.serverIndex, player.GameServerId, player.Id)
+	addToIndex(r.fleetIndex, player.GameServer.Fleet, player.Id)
+}
+
+// unindexGameServer removes the player from the server and fleet indexes for its current GameServerId and GameServer.
+// The caller must hold the write lock.
+func (r *memoryRepository) unindexGameServer(player *model.Player) {
+	if player.GameServerId == "" {
+		return
+	}
+
+	removeFromIndex(r.serverIndex, player.GameServerId, player.Id)
+	removeFromIndex(r.fleetIndex, player.GameServer.Fleet, player.Id)
+}
+
+func addToIndex(index map[string]map[uuid.UUID]struct{}, key string, playerId uuid.UUID) {
+	if key == "" {
+		return
+	}
+
+	entries, ok := index[key]
+	if !ok {
+		entries = make(map[uuid.UUID]struct{})
+		index[key] = entries
+	}
+	entries[playerId] = struct{}{}
+}
+
+func removeFromIndex(index map[string]map[uuid.UUID]struct{}, key string, playerId uuid.UUID) {
+	entries, ok := index[key]
+	if !ok {
+		return
+	}
+
+	delete(entries, playerId)
+	if len(entries) == 0 {
+		delete(index, key)
+	}
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"player-tracker/internal/repository/model"
+	"sync"
+	"time"
+)
+
+type memorySessionRepository struct {
+	SessionRepository
+
+	lock sync.RWMutex
+
+	// sessions holds each player's sessions, oldest first
+	sessions map[uuid.UUID][]*model.Session
+}
+
+// NewMemorySessionRepository creates a SessionRepository that holds all data in process memory.
+// History is unbounded and lost when the process exits, so it is only intended for development and tests.
+func NewMemorySessionRepository() SessionRepository {
+	return &memorySessionRepository{
+		sessions: make(map[uuid.UUID][]*model.Session),
+	}
+}
+
+func (r *memorySessionRepository) Ping(_ context.Context) error {
+	return nil
+}
+
+func (r *memorySessionRepository) Close(_ context.Context) error {
+	return nil
+}
+
+func (r *memorySessionRepository) StartSession(_ context.Context, playerId uuid.UUID, username string, proxyId string, at time.Time) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, session := range r.sessions[playerId] {
+		if session.ProxyId == proxyId && session.ConnectedAt.Equal(at) {
+			return nil
+		}
+	}
+
+	r.endSession(playerId, at)
+	r.sessions[playerId] = append(r.sessions[playerId], &model.Session{
+		Id:          uuid.New(),
+		PlayerId:    playerId,
+		Username:    username,
+		ProxyId:     proxyId,
+		ConnectedAt: at,
+		ServerHops:  []model.ServerHop{},
+	})
+
+	return nil
+}
+
+func (r *memorySessionRepository) AddSessionHop(_ context.Context, playerId uuid.UUID, serverId string, at time.Time) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	session := r.openSession(playerId)
+	if session == nil {
+		session = &model.Session{Id: uuid.New(), PlayerId: playerId, ConnectedAt: at}
+		r.sessions[playerId] = append(r.sessions[playerId], session)
+	}
+
+	for _, hop := range session.ServerHops {
+		if hop.ServerId == serverId && hop.JoinedAt.Equal(at) {
+			return nil
+		}
+	}
+
+	session.ServerHops = append(session.ServerHops, model.ServerHop{ServerId: serverId, JoinedAt: at})
+	return nil
+}
+
+func (r *memorySessionRepository) EndSession(_ context.Context, playerId uuid.UUID, at time.Time) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.endSession(playerId, at)
+	return nil
+}
+
+func (r *memorySessionRepository) GetPlayerSessions(_ context.Context, playerId uuid.UUID, before time.Time, limit int64) ([]*model.Session, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var sessions []*model.Session
+	playerSessions := r.sessions[playerId]
+	for i := len(playerSessions) - 1; i >= 0 && int64(len(sessions)) < limit; i-- {
+		session := playerSessions[i]
+		if !session.ConnectedAt.Before(before) {
+			continue
+		}
+
+		clone := *session
+		clone.ServerHops = append([]model.ServerHop{}, session.ServerHops...)
+		sessions = append(sessions, &clone)
+	}
+
+	return sessions, nil
+}
+
+// openSession returns the player's open session, or nil if there isn't one.
+// The caller must hold the lock.
+func (r *memorySessionRepository) openSession(playerId uuid.UUID) *model.Session {
+	playerSessions := r.sessions[playerId]
+	if len(playerSessions) == 0 {
+		return nil
+	}
+
+	// Only the latest session can be open
+	session := playerSessions[len(playerSessions)-1]
+	if session.DisconnectedAt != nil {
+		return nil
+	}
+	return session
+}
+
+// endSession ends the player's open session, if any.
+// The caller must hold the write lock.
+func (r *memorySessionRepository) endSession(playerId uuid.UUID, at time.Time) {
+	if session := r.openSession(playerId); session != nil {
+		session.DisconnectedAt = &at
+	}
+}
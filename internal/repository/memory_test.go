@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryRepository_Concurrent(t *testing.T) {
+	repo := NewMemoryRepository()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			playerId := uuid.New()
+			_, err := repo.SetPlayerProxy(context.Background(), playerId, "", "proxy-1", time.Time{}, "", AnyVersion)
+			assert.NoError(t, err)
+			_, err = repo.SetPlayerGameServer(context.Background(), playerId, "lobby-z24523-sdhbsd", time.Time{}, "", AnyVersion)
+			assert.NoError(t, err)
+			_, err = repo.GetServerPlayers(context.Background(), "lobby-z24523-sdhbsd")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	counts, err := repo.GetFleetPlayerCounts(context.Background(), []string{"lobby"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"lobby": 100}, counts)
+}
+
+func TestMemoryRepository_Behaviour(t *testing.T) {
+	testRepositoryBehaviour(t, func(t *testing.T) Repository {
+		return NewMemoryRepository()
+	})
+}
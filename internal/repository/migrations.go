@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"player-tracker/internal/config"
+	"player-tracker/internal/repository/model"
+	"sort"
+	"time"
+)
+
+const migrationCollectionName = "migration"
+
+// migration is a single versioned change to the mongo schema, e.g. creating indexes or backfilling a field.
+// Migrations must be idempotent, as two instances starting at the same time may both apply one.
+type migration struct {
+	version     int
+	description string
+	up          func(ctx context.Context, db *mongo.Database) error
+}
+
+// appliedMigration is the record of a migration in the migration collection
+type appliedMigration struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"appliedAt"`
+}
+
+// migrations are applied in order of version. Once released, a migration must never be changed or removed,
+// add a new one to the end instead.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create player gameServerId and proxyId indexes",
+		up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(playerCollectionName).Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: bson.D{{Key: "gameServerId", Value: 1}}},
+				{Keys: bson.D{{Key: "proxyId", Value: 1}}},
+			})
+			return err
+		},
+	},
+	{
+		version:     2,
+		description: "create session playerId and connectedAt index",
+		up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(sessionCollectionName).Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys: bson.D{{Key: "playerId", Value: 1}, {Key: "connectedAt", Value: -1}},
+			})
+			return err
+		},
+	},
+	{
+		version:     3,
+		description: "backfill player version",
+		up: func(ctx context.Context, db *mongo.Database) error {
+			// Players written before versioning are treated as having had a single write
+			_, err := db.Collection(playerCollectionName).UpdateMany(ctx,
+				bson.M{"version": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"version": 1}},
+			)
+			return err
+		},
+	},
+	{
+		version:     4,
+		description: "backfill player game server fleet, deployment and pod and create fleet index",
+		up: func(ctx context.Context, db *mongo.Database) error {
+			collection := db.Collection(playerCollectionName)
+
+			cursor, err := collection.Find(ctx, bson.M{"deployment": bson.M{"$exists": false}})
+			if err != nil {
+				return err
+			}
+
+			var players []model.Player
+			if err := cursor.All(ctx, &players); err != nil {
+				return err
+			}
+
+			for _, player := range players {
+				gameServer, _ := model.ParseServerID(player.GameServerId)
+				_, err := collection.UpdateOne(ctx,
+					bson.M{"_id": player.Id, "deployment": bson.M{"$exists": false}},
+					bson.M{"$set": bson.M{"fleet": gameServer.Fleet, "deployment": gameServer.Deployment, "pod": gameServer.Pod}},
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys: bson.D{{Key: "fleet", Value: 1}},
+			})
+			return err
+		},
+	},
+}
+
+// MigrateMongo connects to mongo and applies all migrations that haven't been applied yet.
+// It returns the versions that were applied, which is empty if the database was already up to date.
+func MigrateMongo(ctx context.Context, cfg *config.MongoDBConfig) ([]int, error) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI).SetRegistry(createCodecRegistry()))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = client.Disconnect(ctx)
+	}()
+
+	return applyMigrations(ctx, client.Database(databaseName), migrations)
+}
+
+func applyMigrations(ctx context.Context, db *mongo.Database, migrations []migration) ([]int, error) {
+	collection := db.Collection(migrationCollectionName)
+
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []appliedMigration
+	if err := cursor.All(ctx, &applied); err != nil {
+		return nil, err
+	}
+
+	appliedVersions := make(map[int]bool, len(applied))
+	for _, m := range applied {
+		appliedVersions[m.Version] = true
+	}
+
+	sorted := append([]migration{}, migrations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].version < sorted[j].version
+	})
+
+	var versions []int
+	for _, m := range sorted {
+		if appliedVersions[m.version] {
+			continue
+		}
+
+		migrationCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		err := m.up(migrationCtx, db)
+		cancel()
+		if err != nil {
+			return versions, fmt.Errorf("failed to apply migration %d (%s): %w", m.version, m.description, err)
+		}
+
+		_, err = collection.InsertOne(ctx, appliedMigration{
+			Version:     m.version,
+			Description: m.description,
+			AppliedAt:   time.Now(),
+		})
+		// Another instance applied the same migration at the same time, which is fine as migrations are idempotent
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return versions, fmt.Errorf("failed to record migration %d: %w", m.version, err)
+		}
+
+		versions = append(versions, m.version)
+	}
+
+	return versions, nil
+}
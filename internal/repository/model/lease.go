@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// ProxyLease is kept for a proxy while it sends heartbeats.
+// Once it expires the proxy is treated as gone, and its players are disconnected.
+type ProxyLease struct {
+	ProxyId   string    `bson:"_id"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
@@ -1,6 +1,9 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+	"time"
+)
 
 type Player struct {
 	Id       uuid.UUID `bson:"_id"`
@@ -8,4 +11,30 @@ type Player struct {
 
 	GameServerId string `bson:"gameServerId"`
 	ProxyId      string `bson:"proxyId"`
+	// GameServer is GameServerId parsed, see ParseServerID.
+	// It is stored inline so the fleet can be queried directly, and is zero if GameServerId isn't valid.
+	GameServer ServerID `bson:",inline"`
+
+	// LastEventAt is when the event of the latest write happened, so older events arriving late can be ignored
+	LastEventAt time.Time `bson:"lastEventAt"`
+	// LastMessageId is the ID of the message of the latest write, so a redelivery of it is known to be a replay.
+	// It is empty if the message had no ID or the write wasn't from a message, such as a purge.
+	LastMessageId string `bson:"lastMessageId"`
+
+	// Version is incremented on every write, starting at 1 when the Player is inserted
+	Version int64 `bson:"version"`
+}
+
+// LastSeen is kept for a Player after they disconnect.
+type LastSeen struct {
+	PlayerId uuid.UUID `bson:"_id"`
+	Username string    `bson:"username"`
+
+	// GameServerId and ProxyId are where the player was when they disconnected
+	GameServerId string `bson:"gameServerId"`
+	ProxyId      string `bson:"proxyId"`
+
+	DisconnectedAt time.Time `bson:"disconnectedAt"`
+	// LastMessageId is the ID of the disconnect message, see Player.LastMessageId
+	LastMessageId string `bson:"lastMessageId"`
 }
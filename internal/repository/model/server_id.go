@@ -0,0 +1,74 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ProxyFleet is the fleet of all proxies
+const ProxyFleet = "proxy"
+
+// ErrInvalidServerID is returned by ParseServerID when an ID isn't in the {fleet}-{deployment}-{pod} format
+var ErrInvalidServerID = errors.New("invalid server id")
+
+// ServerID is a parsed server ID in the format {fleet}-{deployment}-{pod},
+// e.g. block-sumo-3xja3t-qlx35 is the block-sumo fleet, deployment 3xja3t and pod qlx35.
+// The fleet may contain hyphens, the deployment and pod suffixes may not.
+type ServerID struct {
+	Fleet      string `bson:"fleet"`
+	Deployment string `bson:"deployment"`
+	Pod        string `bson:"pod"`
+}
+
+// ParseServerID parses and validates a server ID.
+// All parts must be non-empty and only contain lowercase letters and digits (plus hyphens in the fleet).
+func ParseServerID(id string) (ServerID, error) {
+	parts := strings.Split(id, "-")
+	if len(parts) < 3 {
+		return ServerID{}, fmt.Errorf("%w %q: expected {fleet}-{deployment}-{pod}", ErrInvalidServerID, id)
+	}
+
+	for _, part := range parts {
+		if !isServerIDPart(part) {
+			return ServerID{}, fmt.Errorf("%w %q: parts must be non-empty lowercase alphanumeric", ErrInvalidServerID, id)
+		}
+	}
+
+	return ServerID{
+		Fleet:      strings.Join(parts[:len(parts)-2], "-"),
+		Deployment: parts[len(parts)-2],
+		Pod:        parts[len(parts)-1],
+	}, nil
+}
+
+// IsZero returns true if the ServerID is empty, e.g. because the ID it came from was invalid
+func (id ServerID) IsZero() bool {
+	return id == ServerID{}
+}
+
+// IsProxy returns true if the server is a proxy rather than a game server
+func (id ServerID) IsProxy() bool {
+	return id.Fleet == ProxyFleet
+}
+
+// String returns the ID in the same format it was parsed from
+func (id ServerID) String() string {
+	if id.IsZero() {
+		return ""
+	}
+	return id.Fleet + "-" + id.Deployment + "-" + id.Pod
+}
+
+func isServerIDPart(part string) bool {
+	if part == "" {
+		return false
+	}
+
+	for _, c := range part {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
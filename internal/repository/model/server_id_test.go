@@ -0,0 +1,84 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseServerID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		want    ServerID
+		wantErr error
+	}{
+		{
+			name: "game_server",
+			id:   "lobby-z24523-sdhbsd",
+			want: ServerID{Fleet: "lobby", Deployment: "z24523", Pod: "sdhbsd"},
+		},
+		{
+			name: "hyphenated_fleet",
+			id:   "block-sumo-3xja3t-qlx35",
+			want: ServerID{Fleet: "block-sumo", Deployment: "3xja3t", Pod: "qlx35"},
+		},
+		{
+			name: "multiple_hyphen_fleet",
+			id:   "marathon-racing-sdgwsd-235eax",
+			want: ServerID{Fleet: "marathon-racing", Deployment: "sdgwsd", Pod: "235eax"},
+		},
+		{
+			name: "proxy",
+			id:   "proxy-sdgwsd-235eax",
+			want: ServerID{Fleet: ProxyFleet, Deployment: "sdgwsd", Pod: "235eax"},
+		},
+		{
+			name:    "empty",
+			id:      "",
+			wantErr: ErrInvalidServerID,
+		},
+		{
+			name:    "missing_pod",
+			id:      "lobby-z24523",
+			wantErr: ErrInvalidServerID,
+		},
+		{
+			name:    "empty_part",
+			id:      "lobby--sdhbsd",
+			wantErr: ErrInvalidServerID,
+		},
+		{
+			name:    "trailing_hyphen",
+			id:      "lobby-z24523-sdhbsd-",
+			wantErr: ErrInvalidServerID,
+		},
+		{
+			name:    "uppercase",
+			id:      "Lobby-z24523-sdhbsd",
+			wantErr: ErrInvalidServerID,
+		},
+		{
+			name:    "invalid_character",
+			id:      "lobby-z24523-sdh_bsd",
+			wantErr: ErrInvalidServerID,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseServerID(test.id)
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.want, got)
+
+			if test.wantErr == nil {
+				assert.Equal(t, test.id, got.String())
+			}
+		})
+	}
+}
+
+func TestServerID_IsProxy(t *testing.T) {
+	assert.True(t, ServerID{Fleet: ProxyFleet, Deployment: "sdgwsd", Pod: "235eax"}.IsProxy())
+	assert.False(t, ServerID{Fleet: "lobby", Deployment: "z24523", Pod: "sdhbsd"}.IsProxy())
+	assert.False(t, ServerID{}.IsProxy())
+}
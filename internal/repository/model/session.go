@@ -0,0 +1,26 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Session is a single connection of a player to the network, from connect to disconnect.
+type Session struct {
+	Id       uuid.UUID `bson:"_id"`
+	PlayerId uuid.UUID `bson:"playerId"`
+	Username string    `bson:"username"`
+	ProxyId  string    `bson:"proxyId"`
+
+	ConnectedAt time.Time `bson:"connectedAt"`
+	// DisconnectedAt is nil while the session is ongoing
+	DisconnectedAt *time.Time `bson:"disconnectedAt,omitempty"`
+
+	ServerHops []ServerHop `bson:"serverHops"`
+}
+
+// ServerHop is a player joining a game server during a Session.
+type ServerHop struct {
+	ServerId string    `bson:"serverId"`
+	JoinedAt time.Time `bson:"joinedAt"`
+}
@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"fmt"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/bsoncodec"
@@ -15,15 +14,19 @@ import (
 )
 
 const (
-	databaseName         = "player-tracker"
-	playerCollectionName = "player"
+	databaseName           = "player-tracker"
+	playerCollectionName   = "player"
+	lastSeenCollectionName = "lastSeen"
+	leaseCollectionName    = "proxyLease"
 )
 
 type mongoRepository struct {
 	Repository
 	db *mongo.Database
 
-	playerCollection *mongo.Collection
+	playerCollection   *mongo.Collection
+	lastSeenCollection *mongo.Collection
+	leaseCollection    *mongo.Collection
 }
 
 func NewMongoRepository(ctx context.Context, cfg *config.MongoDBConfig) (Repository, error) {
@@ -34,28 +37,38 @@ func NewMongoRepository(ctx context.Context, cfg *config.MongoDBConfig) (Reposit
 
 	database := client.Database(databaseName)
 	return &mongoRepository{
-		db:               database,
-		playerCollection: database.Collection(playerCollectionName),
+		db:                 database,
+		playerCollection:   database.Collection(playerCollectionName),
+		lastSeenCollection: database.Collection(lastSeenCollectionName),
+		leaseCollection:    database.Collection(leaseCollectionName),
 	}, nil
 }
 
-func (r *mongoRepository) SetPlayerGameServer(ctx context.Context, playerId uuid.UUID, serverId string) error {
+func (r *mongoRepository) Ping(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	res, err := r.playerCollection.UpdateByID(ctx, playerId, bson.M{"$set": bson.M{"gameServerId": serverId}})
-	if err != nil {
-		return err
-	}
+	return r.db.Client().Ping(ctx, nil)
+}
 
-	if res.MatchedCount == 0 {
-		_, err := r.playerCollection.InsertOne(ctx, bson.M{"_id": playerId, "gameServerId": serverId})
-		if err != nil {
-			return err
-		}
-	}
+func (r *mongoRepository) Close(ctx context.Context) error {
+	return r.db.Client().Disconnect(ctx)
+}
 
-	return nil
+func (r *mongoRepository) SetPlayerGameServer(ctx context.Context, playerId uuid.UUID, serverId string, at time.Time, messageId string, expectedVersion int64) (*model.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// An invalid ID is still written, it just has no parts so isn't in any fleet
+	gameServer, _ := model.ParseServerID(serverId)
+	return r.updatePlayer(ctx, playerId, bson.M{
+		"gameServerId":  serverId,
+		"fleet":         gameServer.Fleet,
+		"deployment":    gameServer.Deployment,
+		"pod":           gameServer.Pod,
+		"lastEventAt":   at,
+		"lastMessageId": messageId,
+	}, expectedVersion)
 }
 
 func (r *mongoRepository) GetPlayerGameServer(ctx context.Context, playerId uuid.UUID) (string, error) {
@@ -73,23 +86,12 @@ func (r *mongoRepository) GetPlayerGameServer(ctx context.Context, playerId uuid
 	return res[0].(string), nil
 }
 
-func (r *mongoRepository) SetPlayerProxy(ctx context.Context, playerId uuid.UUID, proxyId string) error {
+func (r *mongoRepository) SetPlayerProxy(ctx context.Context, playerId uuid.UUID, username string, proxyId string, at time.Time, messageId string, expectedVersion int64) (*model.Player, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	res, err := r.playerCollection.UpdateByID(ctx, playerId, bson.M{"$set": bson.M{"proxyId": proxyId}})
-	if err != nil {
-		return err
-	}
-
-	if res.MatchedCount == 0 {
-		_, err := r.playerCollection.InsertOne(ctx, bson.M{"_id": playerId, "proxyId": proxyId})
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return r.updatePlayer(ctx, playerId, bson.M{"username": username, "proxyId": proxyId, "lastEventAt": at,
+		"lastMessageId": messageId}, expectedVersion)
 }
 
 func (r *mongoRepository) GetPlayerProxy(ctx context.Context, playerId uuid.UUID) (string, error) {
@@ -159,12 +161,211 @@ func (r *mongoRepository) DeletePlayer(ctx context.Context, playerId uuid.UUID)
 	return nil
 }
 
+func (r *mongoRepository) DisconnectPlayer(ctx context.Context, playerId uuid.UUID, at time.Time, messageId string, expectedVersion int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": playerId}
+	if expectedVersion != AnyVersion {
+		filter["version"] = expectedVersion
+	}
+
+	var player model.Player
+	err := r.playerCollection.FindOneAndDelete(ctx, filter).Decode(&player)
+	if err == mongo.ErrNoDocuments && expectedVersion != AnyVersion {
+		// Nothing matched, either because the player is offline or because their version has changed
+		online, countErr := r.playerCollection.CountDocuments(ctx, bson.M{"_id": playerId}, options.Count().SetLimit(1))
+		if countErr != nil {
+			return countErr
+		}
+		if online > 0 {
+			return ErrVersionConflict
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = r.lastSeenCollection.ReplaceOne(ctx, bson.M{"_id": playerId}, &model.LastSeen{
+		PlayerId:       playerId,
+		Username:       player.Username,
+		GameServerId:   player.GameServerId,
+		ProxyId:        player.ProxyId,
+		DisconnectedAt: at,
+		LastMessageId:  messageId,
+	}, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *mongoRepository) GetPlayerLastSeen(ctx context.Context, playerId uuid.UUID) (*model.LastSeen, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var lastSeen model.LastSeen
+	err := r.lastSeenCollection.FindOne(ctx, bson.M{"_id": playerId}).Decode(&lastSeen)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lastSeen, nil
+}
+
+// DisconnectProxyPlayers finds the players, then deletes each one as long as they still match, so a player who has had
+// a newer event since they were found is left alone and isn't returned
+func (r *mongoRepository) DisconnectProxyPlayers(ctx context.Context, proxyId string, at time.Time) ([]*model.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	found, err := r.findPurgeable(ctx, bson.M{"proxyId": proxyId}, at)
+	if err != nil {
+		return nil, err
+	}
+	testHookPurge()
+
+	var players []*model.Player
+	var writes []mongo.WriteModel
+	for _, player := range found {
+		filter := purgeFilter(bson.M{"_id": player.Id, "proxyId": proxyId}, at)
+		var deleted model.Player
+		err := r.playerCollection.FindOneAndDelete(ctx, filter).Decode(&deleted)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		players = append(players, &deleted)
+		writes = append(writes, mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": deleted.Id}).SetUpsert(true).
+			SetReplacement(&model.LastSeen{
+				PlayerId:       deleted.Id,
+				Username:       deleted.Username,
+				GameServerId:   deleted.GameServerId,
+				ProxyId:        deleted.ProxyId,
+				DisconnectedAt: at,
+			}))
+	}
+	if len(writes) == 0 {
+		return nil, nil
+	}
+
+	_, err = r.lastSeenCollection.BulkWrite(ctx, writes)
+	if err != nil {
+		return nil, err
+	}
+
+	return players, nil
+}
+
+// ClearServerPlayers finds the players, then clears each one as long as they still match, see DisconnectProxyPlayers
+func (r *mongoRepository) ClearServerPlayers(ctx context.Context, serverId string, at time.Time) ([]*model.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	found, err := r.findPurgeable(ctx, bson.M{"gameServerId": serverId}, at)
+	if err != nil {
+		return nil, err
+	}
+	testHookPurge()
+
+	var players []*model.Player
+	for _, player := range found {
+		filter := purgeFilter(bson.M{"_id": player.Id, "gameServerId": serverId}, at)
+		var cleared model.Player
+		err := r.playerCollection.FindOneAndUpdate(ctx, filter, bson.M{
+			"$set": bson.M{"gameServerId": "", "fleet": "", "deployment": "", "pod": "", "lastEventAt": at,
+				"lastMessageId": ""},
+			"$inc": bson.M{"version": 1},
+		}).Decode(&cleared)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		players = append(players, &cleared)
+	}
+
+	return players, nil
+}
+
+func (r *mongoRepository) RenewProxyLease(ctx context.Context, proxyId string, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.leaseCollection.UpdateOne(ctx, bson.M{"_id": proxyId}, bson.M{"$max": bson.M{"expiresAt": expiresAt}},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *mongoRepository) GetExpiredProxyLeases(ctx context.Context, now time.Time) ([]*model.ProxyLease, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.leaseCollection.Find(ctx, bson.M{"expiresAt": bson.M{"$lt": now}})
+	if err != nil {
+		return nil, err
+	}
+
+	var leases []*model.ProxyLease
+	if err := cursor.All(ctx, &leases); err != nil {
+		return nil, err
+	}
+	return leases, nil
+}
+
+func (r *mongoRepository) DeleteProxyLease(ctx context.Context, lease *model.ProxyLease) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.leaseCollection.DeleteOne(ctx, bson.M{"_id": lease.ProxyId, "expiresAt": lease.ExpiresAt})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount == 1, nil
+}
+
+// findPurgeable finds the players matching filter whose last event was not after at
+func (r *mongoRepository) findPurgeable(ctx context.Context, filter bson.M, at time.Time) ([]*model.Player, error) {
+	cursor, err := r.playerCollection.Find(ctx, purgeFilter(filter, at))
+	if err != nil {
+		return nil, err
+	}
+
+	var players []*model.Player
+	if err := cursor.All(ctx, &players); err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
+// purgeFilter narrows filter to players whose last event was not after at.
+// Players written before lastEventAt was added don't have it, and are matched too.
+func purgeFilter(filter bson.M, at time.Time) bson.M {
+	purge := bson.M{"lastEventAt": bson.M{"$not": bson.M{"$gt": at}}}
+	for key, value := range filter {
+		purge[key] = value
+	}
+	return purge
+}
+
 func (r *mongoRepository) GetServerPlayers(ctx context.Context, serverId string) ([]*model.Player, error) {
+	return r.findPlayers(ctx, bson.M{"gameServerId": serverId})
+}
+
+func (r *mongoRepository) GetProxyPlayers(ctx context.Context, proxyId string) ([]*model.Player, error) {
+	return r.findPlayers(ctx, bson.M{"proxyId": proxyId})
+}
+
+// findPlayers returns the players matching filter
+func (r *mongoRepository) findPlayers(ctx context.Context, filter bson.M) ([]*model.Player, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	var players []*model.Player
-	cursor, err := r.playerCollection.Find(ctx, bson.M{"gameServerId": serverId})
+	cursor, err := r.playerCollection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -182,21 +383,77 @@ func (r *mongoRepository) GetServerPlayers(ctx context.Context, serverId string)
 	return players, nil
 }
 
-func (r *mongoRepository) GetServerPlayerCount(ctx context.Context, targetId string, proxy bool) (int64, error) {
+func (r *mongoRepository) GetServerPlayerCount(ctx context.Context, serverId model.ServerID) (int64, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	if proxy {
-		return r.playerCollection.CountDocuments(ctx, bson.M{"proxyId": targetId})
+	if serverId.IsProxy() {
+		return r.playerCollection.CountDocuments(ctx, bson.M{"proxyId": serverId.String()})
 	}
-	return r.playerCollection.CountDocuments(ctx, bson.M{"gameServerId": targetId})
+	return r.playerCollection.CountDocuments(ctx, bson.M{"gameServerId": serverId.String()})
 }
 
-func (r *mongoRepository) GetServerTypePlayerCount(ctx context.Context, fleetName string) (int64, error) {
+func (r *mongoRepository) GetFleetPlayerCounts(ctx context.Context, fleets []string) (map[string]int64, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	return r.playerCollection.CountDocuments(ctx, bson.M{"gameServerId": bson.M{"$regex": fmt.Sprintf("^%s", fleetName+"-")}})
+	counts := make(map[string]int64, len(fleets))
+	for _, fleet := range fleets {
+		counts[fleet] = 0
+	}
+	if len(fleets) == 0 {
+		return counts, nil
+	}
+
+	cursor, err := r.playerCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"fleet": bson.M{"$in": fleets}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$fleet", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Fleet string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		counts[result.Fleet] = result.Count
+	}
+
+	return counts, nil
+}
+
+func (r *mongoRepository) GetProxyPlayerCounts(ctx context.Context) (map[string]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.playerCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"proxyId": bson.M{"$ne": ""}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$proxyId", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		ProxyId string `bson:"_id"`
+		Count   int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(results))
+	for _, result := range results {
+		counts[result.ProxyId] = result.Count
+	}
+
+	return counts, nil
 }
 
 func (r *mongoRepository) PlayerCount(ctx context.Context) (int64, error) {
@@ -206,6 +463,37 @@ func (r *mongoRepository) PlayerCount(ctx context.Context) (int64, error) {
 	return r.playerCollection.CountDocuments(ctx, bson.M{})
 }
 
+// updatePlayer sets the fields and increments the version of the player in a single upsert.
+// The version is part of the filter when expectedVersion isn't AnyVersion, so a stale write either matches nothing
+// or, when an upsert is allowed, fails on the unique _id index.
+func (r *mongoRepository) updatePlayer(ctx context.Context, playerId uuid.UUID, set bson.M, expectedVersion int64) (*model.Player, error) {
+	filter := bson.M{"_id": playerId}
+	if expectedVersion != AnyVersion {
+		filter["version"] = expectedVersion
+	}
+
+	// A document can only be inserted if we aren't expecting it to already exist
+	upsert := expectedVersion == AnyVersion || expectedVersion == 0
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	opts := options.FindOneAndUpdate().SetUpsert(upsert).SetReturnDocument(options.After)
+
+	var player model.Player
+	err := r.playerCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&player)
+	if mongo.IsDuplicateKeyError(err) && expectedVersion == AnyVersion {
+		// Two upserts for the same new player raced and the other one inserted first, so this is now a plain update
+		err = r.playerCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&player)
+	}
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments || mongo.IsDuplicateKeyError(err) {
+			return nil, ErrVersionConflict
+		}
+		return nil, err
+	}
+
+	return &player, nil
+}
+
 func createCodecRegistry() *bsoncodec.Registry {
 	return bson.NewRegistryBuilder().
 		RegisterTypeEncoder(registrytypes.UUIDType, bsoncodec.ValueEncoderFunc(registrytypes.UuidEncodeValue)).
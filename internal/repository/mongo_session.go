@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"player-tracker/internal/config"
+	"player-tracker/internal/repository/model"
+	"strconv"
+	"time"
+)
+
+const sessionCollectionName = "session"
+
+// openSessionFilter matches the sessions of a player that haven't been ended
+func openSessionFilter(playerId uuid.UUID) bson.M {
+	return bson.M{"playerId": playerId, "disconnectedAt": bson.M{"$exists": false}}
+}
+
+// sessionId is the ID of the session started by a connect, derived from it so a retried connect can't start another
+func sessionId(playerId uuid.UUID, proxyId string, at time.Time) uuid.UUID {
+	return uuid.NewSHA1(playerId, []byte(proxyId+"@"+strconv.FormatInt(at.UnixMilli(), 10)))
+}
+
+type mongoSessionRepository struct {
+	SessionRepository
+	db *mongo.Database
+	// ownsClient is whether Close disconnects the client, it doesn't if the client is shared with a Repository
+	ownsClient bool
+
+	sessionCollection *mongo.Collection
+}
+
+// NewMongoSessionRepository connects a SessionRepository to MongoDB, see NewSessionRepository to share a client
+func NewMongoSessionRepository(ctx context.Context, cfg *config.MongoDBConfig) (SessionRepository, error) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI).SetRegistry(createCodecRegistry()))
+	if err != nil {
+		return nil, err
+	}
+
+	return newMongoSessionRepository(client.Database(databaseName), true), nil
+}
+
+func newMongoSessionRepository(database *mongo.Database, ownsClient bool) *mongoSessionRepository {
+	return &mongoSessionRepository{
+		db:                database,
+		ownsClient:        ownsClient,
+		sessionCollection: database.Collection(sessionCollectionName),
+	}
+}
+
+func (r *mongoSessionRepository) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return r.db.Client().Ping(ctx, nil)
+}
+
+func (r *mongoSessionRepository) Close(ctx context.Context) error {
+	if !r.ownsClient {
+		return nil
+	}
+	return r.db.Client().Disconnect(ctx)
+}
+
+func (r *mongoSessionRepository) StartSession(ctx context.Context, playerId uuid.UUID, username string, proxyId string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// The ID is unique, so the session is only inserted once however many times or places the connect is handled
+	id := sessionId(playerId, proxyId, at)
+	_, err := r.sessionCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$setOnInsert": bson.M{
+		"playerId":    playerId,
+		"username":    username,
+		"proxyId":     proxyId,
+		"connectedAt": at,
+		"serverHops":  []model.ServerHop{},
+	}}, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	// Repeated on a retry in case it failed, sessions started after this one are left open as the player reconnected
+	filter := openSessionFilter(playerId)
+	filter["_id"] = bson.M{"$ne": id}
+	filter["connectedAt"] = bson.M{"$lte": at}
+	_, err = r.sessionCollection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"disconnectedAt": at}})
+	return err
+}
+
+func (r *mongoSessionRepository) AddSessionHop(ctx context.Context, playerId uuid.UUID, serverId string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// $addToSet rather than $push, so a hop that was already added isn't added again
+	_, err := r.sessionCollection.UpdateOne(ctx, openSessionFilter(playerId), bson.M{
+		"$addToSet":    bson.M{"serverHops": model.ServerHop{ServerId: serverId, JoinedAt: at}},
+		"$setOnInsert": bson.M{"_id": uuid.New(), "connectedAt": at},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *mongoSessionRepository) EndSession(ctx context.Context, playerId uuid.UUID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.sessionCollection.UpdateMany(ctx, openSessionFilter(playerId), bson.M{"$set": bson.M{"disconnectedAt": at}})
+	return err
+}
+
+func (r *mongoSessionRepository) GetPlayerSessions(ctx context.Context, playerId uuid.UUID, before time.Time, limit int64) ([]*model.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.sessionCollection.Find(ctx,
+		bson.M{"playerId": playerId, "connectedAt": bson.M{"$lt": before}},
+		options.Find().SetSort(bson.M{"connectedAt": -1}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*model.Session
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
@@ -15,6 +15,7 @@ import (
 	"player-tracker/internal/config"
 	"player-tracker/internal/repository/model"
 	"testing"
+	"time"
 )
 
 const (
@@ -22,9 +23,10 @@ const (
 )
 
 var (
-	dbClient *mongo.Client
-	database *mongo.Database
-	repo     Repository
+	dbClient    *mongo.Client
+	database    *mongo.Database
+	repo        Repository
+	sessionRepo SessionRepository
 )
 
 func TestMain(m *testing.M) {
@@ -35,7 +37,9 @@ func TestMain(m *testing.M) {
 
 	err = pool.Client.Ping()
 	if err != nil {
-		log.Fatalf("could not connect to docker: %s", err)
+		// The in-memory repository tests don't need docker, so only the mongo tests are skipped
+		log.Printf("could not connect to docker, skipping mongo tests: %s", err)
+		os.Exit(m.Run())
 	}
 
 	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
@@ -69,6 +73,12 @@ func TestMain(m *testing.M) {
 
 		// Ping was successful, let's create the mongo repo
 		repo, err = NewMongoRepository(context.Background(), &config.MongoDBConfig{URI: uri})
+		if err != nil {
+			return
+		}
+		// Shares the repository's client, as it does when running
+		sessionRepo, err = NewSessionRepository(context.Background(),
+			&config.Config{MongoDB: &config.MongoDBConfig{URI: uri}}, repo)
 		database = dbClient.Database(databaseName)
 		return
 	})
@@ -91,6 +101,8 @@ func TestMain(m *testing.M) {
 }
 
 func TestMongoRepository_SetPlayerGameServer(t *testing.T) {
+	requireMongo(t)
+
 	playerId := uuid.New()
 	serverId := "lobby-z24523-sdhbsd"
 	proxyId := "proxy-sdgwsd-235eax"
@@ -117,6 +129,8 @@ func TestMongoRepository_SetPlayerGameServer(t *testing.T) {
 				{
 					Id:           playerId,
 					GameServerId: serverId,
+					GameServer:   model.ServerID{Fleet: "lobby", Deployment: "z24523", Pod: "sdhbsd"},
+					Version:      1,
 				},
 			},
 		},
@@ -139,6 +153,8 @@ func TestMongoRepository_SetPlayerGameServer(t *testing.T) {
 					Id:           playerId,
 					GameServerId: serverId,
 					ProxyId:      proxyId,
+					GameServer:   model.ServerID{Fleet: "lobby", Deployment: "z24523", Pod: "sdhbsd"},
+					Version:      1,
 				},
 			},
 		},
@@ -153,7 +169,7 @@ func TestMongoRepository_SetPlayerGameServer(t *testing.T) {
 				assert.NoError(t, err)
 			}
 
-			err := repo.SetPlayerGameServer(context.Background(), test.args.playerId, test.args.serverId)
+			_, err := repo.SetPlayerGameServer(context.Background(), test.args.playerId, test.args.serverId, time.Time{}, "", AnyVersion)
 			assert.Equal(t, test.wantErr, err)
 
 			// Check the database contents
@@ -170,6 +186,8 @@ func TestMongoRepository_SetPlayerGameServer(t *testing.T) {
 }
 
 func TestMongoRepository_SetPlayerProxy(t *testing.T) {
+	requireMongo(t)
+
 	playerId := uuid.New()
 	serverId := "lobby-z24523-sdhbsd"
 	proxyId := "proxy-sdgwsd-235eax"
@@ -212,6 +230,7 @@ func TestMongoRepository_SetPlayerProxy(t *testing.T) {
 					Id:           playerId,
 					GameServerId: serverId,
 					ProxyId:      proxyId,
+					Version:      1,
 				},
 			},
 		},
@@ -227,7 +246,7 @@ func TestMongoRepository_SetPlayerProxy(t *testing.T) {
 				assert.NoError(t, err)
 			}
 
-			err := repo.SetPlayerProxy(context.Background(), test.args.playerId, test.args.proxyId)
+			_, err := repo.SetPlayerProxy(context.Background(), test.args.playerId, "", test.args.proxyId, time.Time{}, "", AnyVersion)
 			assert.Equal(t, test.wantErr, err)
 
 			// Check the database contents
@@ -242,6 +261,8 @@ func TestMongoRepository_SetPlayerProxy(t *testing.T) {
 }
 
 func TestMongoRepository_GetPlayer(t *testing.T) {
+	requireMongo(t)
+
 	playerId := uuid.New()
 	serverId := "lobby-z24523-sdhbsd"
 	proxyId := "proxy-sdgwsd-235eax"
@@ -294,6 +315,8 @@ func TestMongoRepository_GetPlayer(t *testing.T) {
 }
 
 func TestMongoRepository_DeletePlayer(t *testing.T) {
+	requireMongo(t)
+
 	playerId := uuid.New()
 	serverId := "lobby-z24523-sdhbsd"
 	proxyId := "proxy-sdgwsd-235eax"
@@ -338,25 +361,25 @@ func TestMongoRepository_DeletePlayer(t *testing.T) {
 }
 
 func TestMongoRepository_GetServerPlayerCount(t *testing.T) {
+	requireMongo(t)
+
 	playerIds := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
-	serverIds := []string{"lobby-1", "lobby-2", "lobby-3"}
-	proxyIds := []string{"proxy-1", "proxy-2", "proxy-3"}
+	serverIds := []string{"lobby-z24523-sdhbsd", "lobby-z24523-2ndjd2", "lobby-3xja3t-qlx35"}
+	proxyIds := []string{"proxy-sdgwsd-235eax", "proxy-hsdjrn-2ndjd2", "proxy-hsdjrn-qlx35"}
 
 	tests := []struct {
-		name            string
-		data            []model.Player
-		serverOrProxyId string
-		proxy           bool
-		want            int64
-		wantErr         error
+		name     string
+		data     []model.Player
+		serverId string
+		want     int64
+		wantErr  error
 	}{
 		{
-			name:            "empty",
-			data:            nil,
-			serverOrProxyId: serverIds[0],
-			proxy:           false,
-			want:            0,
-			wantErr:         nil,
+			name:     "empty",
+			data:     nil,
+			serverId: serverIds[0],
+			want:     0,
+			wantErr:  nil,
 		},
 		{
 			name: "valid_multiple_servers",
@@ -377,10 +400,9 @@ func TestMongoRepository_GetServerPlayerCount(t *testing.T) {
 					ProxyId:      proxyIds[2],
 				},
 			},
-			serverOrProxyId: serverIds[0],
-			proxy:           false,
-			want:            2,
-			wantErr:         nil,
+			serverId: serverIds[0],
+			want:     2,
+			wantErr:  nil,
 		},
 		{
 			name: "proxy_count",
@@ -401,10 +423,9 @@ func TestMongoRepository_GetServerPlayerCount(t *testing.T) {
 					ProxyId:      proxyIds[1], // Different proxy
 				},
 			},
-			serverOrProxyId: proxyIds[0],
-			proxy:           true,
-			want:            2,
-			wantErr:         nil,
+			serverId: proxyIds[0],
+			want:     2,
+			wantErr:  nil,
 		},
 	}
 
@@ -417,55 +438,60 @@ func TestMongoRepository_GetServerPlayerCount(t *testing.T) {
 				assert.NoError(t, err)
 			}
 
-			got, err := repo.GetServerPlayerCount(context.Background(), test.serverOrProxyId, test.proxy)
+			got, err := repo.GetServerPlayerCount(context.Background(), mustParseServerID(t, test.serverId))
 			assert.Equal(t, test.wantErr, err)
 			assert.Equal(t, test.want, got)
 		})
 	}
 }
 
-func TestMongoRepository_GetServerTypePlayerCount(t *testing.T) {
+func TestMongoRepository_GetFleetPlayerCounts(t *testing.T) {
+	requireMongo(t)
+
 	playerIds := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
 
 	fleetIds := []string{"lobby", "block-sumo"}
-	serverIds := []string{"lobby-1", "block-sumo-2"}
+	serverIds := []string{"lobby-z24523-sdhbsd", "block-sumo-2ndkfs-dfd2x"}
 	proxyIds := []string{"proxy-1", "proxy-2", "proxy-3"}
 
 	tests := []struct {
 		name    string
 		data    []model.Player
-		fleetId string
-		want    int64
+		fleets  []string
+		want    map[string]int64
 		wantErr error
 	}{
 		{
 			name:    "empty",
 			data:    nil,
-			fleetId: fleetIds[0],
-			want:    0,
+			fleets:  fleetIds,
+			want:    map[string]int64{"lobby": 0, "block-sumo": 0},
 			wantErr: nil,
 		},
 		{
-			name: "valid_multiple_servers",
+			name: "valid_multiple_fleets",
 			data: []model.Player{
 				{
 					Id:           playerIds[0],
 					GameServerId: serverIds[0],
 					ProxyId:      proxyIds[0],
+					GameServer:   model.ServerID{Fleet: fleetIds[0], Deployment: "z24523", Pod: "sdhbsd"},
 				},
 				{
 					Id:           playerIds[1],
 					GameServerId: serverIds[0], // Same server
 					ProxyId:      proxyIds[1],
+					GameServer:   model.ServerID{Fleet: fleetIds[0], Deployment: "z24523", Pod: "sdhbsd"},
 				},
 				{
 					Id:           playerIds[2],
-					GameServerId: serverIds[1], // Different server
+					GameServerId: serverIds[1], // Different fleet
 					ProxyId:      proxyIds[2],
+					GameServer:   model.ServerID{Fleet: fleetIds[1], Deployment: "2ndkfs", Pod: "dfd2x"},
 				},
 			},
-			fleetId: fleetIds[0],
-			want:    2,
+			fleets:  append(fleetIds, "marathon"),
+			want:    map[string]int64{"lobby": 2, "block-sumo": 1, "marathon": 0},
 			wantErr: nil,
 		},
 	}
@@ -479,13 +505,79 @@ func TestMongoRepository_GetServerTypePlayerCount(t *testing.T) {
 				assert.NoError(t, err)
 			}
 
-			got, err := repo.GetServerTypePlayerCount(context.Background(), test.fleetId)
+			got, err := repo.GetFleetPlayerCounts(context.Background(), test.fleets)
 			assert.Equal(t, test.wantErr, err)
 			assert.Equal(t, test.want, got)
 		})
 	}
 }
 
+func TestMongoRepository_Behaviour(t *testing.T) {
+	requireMongo(t)
+
+	testRepositoryBehaviour(t, func(t *testing.T) Repository {
+		t.Cleanup(cleanup())
+		return repo
+	})
+}
+
+func TestMongoSessionRepository_Behaviour(t *testing.T) {
+	requireMongo(t)
+
+	testSessionRepositoryBehaviour(t, func(t *testing.T) SessionRepository {
+		t.Cleanup(cleanup())
+		return sessionRepo
+	})
+}
+
+func TestMongo_ApplyMigrations(t *testing.T) {
+	requireMongo(t)
+	t.Cleanup(cleanup())
+
+	ctx := context.Background()
+	playerId := uuid.New()
+
+	// A player written before versioning and the game server fields existed
+	_, err := database.Collection(playerCollectionName).InsertOne(ctx, bson.M{"_id": playerId, "proxyId": "proxy-sdgwsd-235eax",
+		"gameServerId": "block-sumo-2ndkfs-dfd2x"})
+	assert.NoError(t, err)
+
+	applied, err := applyMigrations(ctx, database, migrations)
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(migrations))
+
+	player, err := repo.GetPlayer(ctx, playerId)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), player.Version)
+	assert.Equal(t, model.ServerID{Fleet: "block-sumo", Deployment: "2ndkfs", Pod: "dfd2x"}, player.GameServer)
+
+	cursor, err := database.Collection(playerCollectionName).Indexes().List(ctx)
+	assert.NoError(t, err)
+	var indexes []bson.M
+	assert.NoError(t, cursor.All(ctx, &indexes))
+	assert.Len(t, indexes, 4) // _id, gameServerId, proxyId, fleet
+
+	// Everything is already applied, so a second run is a no-op
+	applied, err = applyMigrations(ctx, database, migrations)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	// Only migrations that haven't been applied are run
+	var ran []int
+	extra := append(migrations, migration{
+		version:     len(migrations) + 1,
+		description: "test",
+		up: func(ctx context.Context, db *mongo.Database) error {
+			ran = append(ran, len(migrations)+1)
+			return nil
+		},
+	})
+	applied, err = applyMigrations(ctx, database, extra)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{len(migrations) + 1}, applied)
+	assert.Equal(t, applied, ran)
+}
+
 func convertToInterfaceSlice[T any](data []T) []interface{} {
 	var result []interface{}
 	for _, player := range data {
@@ -494,6 +586,12 @@ func convertToInterfaceSlice[T any](data []T) []interface{} {
 	return result
 }
 
+func requireMongo(t *testing.T) {
+	if database == nil {
+		t.Skip("mongo is not available")
+	}
+}
+
 func cleanup() func() {
 	return func() {
 		if err := database.Drop(context.TODO()); err != nil {
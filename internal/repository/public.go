@@ -2,26 +2,112 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+	"player-tracker/internal/config"
 	"player-tracker/internal/repository/model"
+	"time"
 )
 
+const (
+	TypeMongoDB = "mongodb"
+	TypeMemory  = "memory"
+	TypeRedis   = "redis"
+)
+
+// ErrNotFound is returned by all repository implementations when a Player does not exist.
+// It is the same value as mongo.ErrNoDocuments so existing comparisons keep working.
+var ErrNotFound = mongo.ErrNoDocuments
+
+// ErrVersionConflict is returned by a write when the stored Player's version is not the expected version.
+var ErrVersionConflict = errors.New("player version conflict")
+
+// AnyVersion can be passed as the expected version of a write to apply it whatever the stored version is.
+// Passing 0 means the Player must not exist yet.
+const AnyVersion int64 = -1
+
 // Repository contains methods for all repository implementations.
-// All Set methods should insert if the Player is not already present
+// All Set methods should insert if the Player is not already present.
+// Only online players are returned by the Player queries and counts, offline players only have a LastSeen.
+//
+// Each Set method is a single atomic operation that increments the Player's version and returns the updated Player.
+// If expectedVersion is not AnyVersion and doesn't match the stored version, nothing is written and
+// ErrVersionConflict is returned, so callers can re-read the Player and retry.
+// As each Set method only writes its own fields, concurrent writes of different fields are merged.
+// Each Set method also sets the Player's LastEventAt to at, the time of the event being applied,
+// and LastMessageId to messageId, the ID of the message being applied.
 type Repository interface {
-	SetPlayerGameServer(ctx context.Context, playerId uuid.UUID, serverId string) error
+	SetPlayerGameServer(ctx context.Context, playerId uuid.UUID, serverId string, at time.Time, messageId string, expectedVersion int64) (*model.Player, error)
 
-	SetPlayerProxy(ctx context.Context, playerId uuid.UUID, proxyId string) error
+	SetPlayerProxy(ctx context.Context, playerId uuid.UUID, username string, proxyId string, at time.Time, messageId string, expectedVersion int64) (*model.Player, error)
 
 	GetPlayer(ctx context.Context, playerId uuid.UUID) (*model.Player, error)
 	GetPlayers(ctx context.Context, playerIds []uuid.UUID) ([]*model.Player, error)
 	DeletePlayer(ctx context.Context, playerId uuid.UUID) error
 
+	// DisconnectPlayer removes the online Player and keeps their last location as a LastSeen,
+	// with the ID of the disconnect message. Returns ErrNotFound if the player is not online.
+	// Like the Set methods, it returns ErrVersionConflict without writing anything if expectedVersion is not
+	// AnyVersion and doesn't match the stored version.
+	DisconnectPlayer(ctx context.Context, playerId uuid.UUID, at time.Time, messageId string, expectedVersion int64) error
+	// GetPlayerLastSeen returns where the player was when they last disconnected.
+	// Returns ErrNotFound if the player has never disconnected.
+	GetPlayerLastSeen(ctx context.Context, playerId uuid.UUID) (*model.LastSeen, error)
+
+	// DisconnectProxyPlayers disconnects every player on the proxy whose LastEventAt is not after at, as
+	// DisconnectPlayer does, for when the proxy has gone without sending their disconnects.
+	// Returns the players as they were before they were disconnected.
+	DisconnectProxyPlayers(ctx context.Context, proxyId string, at time.Time) ([]*model.Player, error)
+	// ClearServerPlayers removes every player on the game server whose LastEventAt is not after at from it,
+	// leaving them connected to their proxy without a game server, for when the server has gone.
+	// Each player's LastEventAt is set to at, their LastMessageId is cleared and their version is incremented.
+	// Returns the players as they were before they were cleared.
+	ClearServerPlayers(ctx context.Context, serverId string, at time.Time) ([]*model.Player, error)
+
+	// RenewProxyLease extends the proxy's lease until expiresAt, creating it if it doesn't exist.
+	// A lease is never shortened, so a heartbeat that arrives late can't bring its expiry forward.
+	RenewProxyLease(ctx context.Context, proxyId string, expiresAt time.Time) error
+	// GetExpiredProxyLeases returns the leases that expired before now
+	GetExpiredProxyLeases(ctx context.Context, now time.Time) ([]*model.ProxyLease, error)
+	// DeleteProxyLease deletes the lease if it hasn't been renewed since it was read, returning whether it was deleted.
+	// Only one of several callers deleting the same lease gets true, so only it expires the proxy.
+	DeleteProxyLease(ctx context.Context, lease *model.ProxyLease) (bool, error)
+
 	GetServerPlayers(ctx context.Context, serverId string) ([]*model.Player, error)
-	GetServerPlayerCount(ctx context.Context, serverId string, proxy bool) (int64, error)
+	// GetProxyPlayers returns the players connected to the proxy
+	GetProxyPlayers(ctx context.Context, proxyId string) ([]*model.Player, error)
+	// GetServerPlayerCount returns the number of players on a game server, or connected to a proxy if it's in the proxy fleet
+	GetServerPlayerCount(ctx context.Context, serverId model.ServerID) (int64, error)
 
-	// GetServerTypePlayerCount returns the number of players on a server type
-	// where fleetName is the prefix of the server type (e.g. {fleetName}-3xja3t-qlx35)
-	GetServerTypePlayerCount(ctx context.Context, fleetName string) (int64, error)
+	// GetFleetPlayerCounts returns the number of players in each of the fleets, see model.ServerID.
+	// Every fleet is present in the result, with a count of 0 if it has no players.
+	GetFleetPlayerCounts(ctx context.Context, fleets []string) (map[string]int64, error)
+	// GetProxyPlayerCounts returns the number of players connected to each proxy that has any players
+	GetProxyPlayerCounts(ctx context.Context) (map[string]int64, error)
 	PlayerCount(ctx context.Context) (int64, error)
+
+	// Ping checks that the backend can be reached
+	Ping(ctx context.Context) error
+	// Close disconnects from the backend, the Repository can't be used afterwards
+	Close(ctx context.Context) error
 }
+
+// NewRepository creates the Repository implementation selected by cfg.Repository
+func NewRepository(ctx context.Context, cfg *config.Config) (Repository, error) {
+	switch cfg.Repository {
+	case TypeMongoDB, "":
+		return NewMongoRepository(ctx, cfg.MongoDB)
+	case TypeMemory:
+		return NewMemoryRepository(), nil
+	case TypeRedis:
+		return NewRedisRepository(ctx, cfg.Redis)
+	default:
+		return nil, fmt.Errorf("unknown repository type %s", cfg.Repository)
+	}
+}
+
+// testHookPurge is called by DisconnectProxyPlayers and ClearServerPlayers before the players are purged, for MongoDB
+// after they have been found, so tests can write a player while the purge is in progress
+var testHookPurge = func() {}
@@ -0,0 +1,640 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"player-tracker/internal/config"
+	"player-tracker/internal/repository/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// redisKeyPrefix is a hash tag, so every key is in the same Redis Cluster slot, see redisIndexFunctions
+	redisKeyPrefix = "{player-tracker}:"
+
+	// redisPlayersKey is a set of all player IDs, used for PlayerCount
+	redisPlayersKey = redisKeyPrefix + "players"
+	// redisPlayerKeyPrefix is the prefix of the hash holding each player's fields
+	redisPlayerKeyPrefix = redisKeyPrefix + "player:"
+	// redisServerKeyPrefix is the prefix of the set of player IDs on each game server
+	redisServerKeyPrefix = redisKeyPrefix + "server:"
+	// redisProxyKeyPrefix is the prefix of the set of player IDs on each proxy
+	redisProxyKeyPrefix = redisKeyPrefix + "proxy:"
+	// redisLastSeenKeyPrefix is the prefix of the hash holding each offline player's last location
+	redisLastSeenKeyPrefix = redisKeyPrefix + "last-seen:"
+	// redisFleetKeyPrefix is the prefix of the set of player IDs in each fleet, see model.ServerID
+	redisFleetKeyPrefix = redisKeyPrefix + "fleet:"
+	// redisLeasesKey is a sorted set of proxy IDs scored by when their lease expires (unix ms)
+	redisLeasesKey = redisKeyPrefix + "proxy-leases"
+)
+
+// redisIndexFunctions are shared by all scripts to keep the server and fleet sets in sync with the player hash.
+// The fleet is stored in the player hash so it can be unindexed without being derived again.
+//
+// Which sets a player is in is only known once their hash is read inside the script, so the set keys are built from
+// the key prefix rather than passed in KEYS. The prefix is a hash tag, so they are in the same slot as the keys that
+// are passed, which Redis Cluster routes the script by.
+const redisIndexFunctions = `
+local prefix = ARGV[1]
+
+local function unindexGameServer(playerId, serverId, fleet)
+	if not serverId or serverId == '' then return end
+	redis.call('SREM', prefix .. 'server:' .. serverId, playerId)
+	if fleet and fleet ~= '' then
+		redis.call('SREM', prefix .. 'fleet:' .. fleet, playerId)
+	end
+end
+
+local function indexGameServer(playerId, serverId, fleet)
+	if not serverId or serverId == '' then return end
+	redis.call('SADD', prefix .. 'server:' .. serverId, playerId)
+	if fleet and fleet ~= '' then
+		redis.call('SADD', prefix .. 'fleet:' .. fleet, playerId)
+	end
+end
+
+local function unindexProxy(playerId, proxyId)
+	if not proxyId or proxyId == '' then return end
+	redis.call('SREM', prefix .. 'proxy:' .. proxyId, playerId)
+end
+
+local function indexProxy(playerId, proxyId)
+	if not proxyId or proxyId == '' then return end
+	redis.call('SADD', prefix .. 'proxy:' .. proxyId, playerId)
+end
+
+-- isAfter returns true if the time, see redisTime, is after the other in unix ms. A missing or zero time is never after.
+local function isAfter(time, other)
+	if not time or time == '' then return false end
+	return tonumber(time) > tonumber(other)
+end
+
+-- checkVersion returns false if expectedVersion is not -1 (AnyVersion) and doesn't match the stored version
+local function checkVersion(playerKey, expectedVersion)
+	if expectedVersion == '-1' then return true end
+	local version = redis.call('HGET', playerKey, 'version') or '0'
+	return version == expectedVersion
+end
+`
+
+var (
+	// KEYS[1] = player hash, KEYS[2] = players set, ARGV[1] = key prefix, ARGV[2] = player ID,
+	// ARGV[3] = expected version, ARGV[4] = game server ID, ARGV[5] = fleet, ARGV[6] = deployment, ARGV[7] = pod, ARGV[8] = event time, see redisTime,
+	// ARGV[9] = message ID
+	// Returns the updated player hash, or nil on a version conflict
+	setGameServerScript = redis.NewScript(redisIndexFunctions + `
+local playerId = ARGV[2]
+if not checkVersion(KEYS[1], ARGV[3]) then return nil end
+local previous = redis.call('HMGET', KEYS[1], 'gameServerId', 'fleet')
+unindexGameServer(playerId, previous[1], previous[2])
+redis.call('HSET', KEYS[1], 'gameServerId', ARGV[4], 'fleet', ARGV[5], 'deployment', ARGV[6], 'pod', ARGV[7],
+	'lastEventAt', ARGV[8], 'lastMessageId', ARGV[9])
+indexGameServer(playerId, ARGV[4], ARGV[5])
+redis.call('HINCRBY', KEYS[1], 'version', 1)
+redis.call('SADD', KEYS[2], playerId)
+return redis.call('HGETALL', KEYS[1])
+`)
+
+	// KEYS[1] = player hash, KEYS[2] = players set, ARGV[1] = key prefix, ARGV[2] = player ID,
+	// ARGV[3] = expected version, ARGV[4] = username, ARGV[5] = proxy ID, ARGV[6] = event time, see redisTime, ARGV[7] = message ID
+	// Returns the updated player hash, or nil on a version conflict
+	setProxyScript = redis.NewScript(redisIndexFunctions + `
+local playerId = ARGV[2]
+if not checkVersion(KEYS[1], ARGV[3]) then return nil end
+unindexProxy(playerId, redis.call('HGET', KEYS[1], 'proxyId'))
+redis.call('HSET', KEYS[1], 'username', ARGV[4], 'proxyId', ARGV[5], 'lastEventAt', ARGV[6], 'lastMessageId', ARGV[7])
+indexProxy(playerId, ARGV[5])
+redis.call('HINCRBY', KEYS[1], 'version', 1)
+redis.call('SADD', KEYS[2], playerId)
+return redis.call('HGETALL', KEYS[1])
+`)
+
+	// KEYS[1] = player hash, KEYS[2] = players set, ARGV[1] = key prefix, ARGV[2] = player ID
+	// Returns 0 if the player did not exist
+	deletePlayerScript = redis.NewScript(redisIndexFunctions + `
+local playerId = ARGV[2]
+if redis.call('EXISTS', KEYS[1]) == 0 then return 0 end
+local fields = redis.call('HMGET', KEYS[1], 'gameServerId', 'proxyId', 'fleet')
+unindexGameServer(playerId, fields[1], fields[3])
+unindexProxy(playerId, fields[2])
+redis.call('DEL', KEYS[1])
+redis.call('SREM', KEYS[2], playerId)
+return 1
+`)
+
+	// KEYS[1] = player hash, KEYS[2] = last seen hash, KEYS[3] = players set, ARGV[1] = key prefix, ARGV[2] = player ID,
+	// ARGV[3] = disconnect time (unix ms), ARGV[4] = message ID, ARGV[5] = expected version
+	// Returns 0 if the player was not online, or -1 on a version conflict
+	disconnectPlayerScript = redis.NewScript(redisIndexFunctions + `
+local playerId = ARGV[2]
+if redis.call('EXISTS', KEYS[1]) == 0 then return 0 end
+if not checkVersion(KEYS[1], ARGV[5]) then return -1 end
+local fields = redis.call('HMGET', KEYS[1], 'gameServerId', 'proxyId', 'username', 'fleet')
+unindexGameServer(playerId, fields[1], fields[4])
+unindexProxy(playerId, fields[2])
+redis.call('DEL', KEYS[1], KEYS[2])
+redis.call('SREM', KEYS[3], playerId)
+redis.call('HSET', KEYS[2], 'gameServerId', fields[1] or '', 'proxyId', fields[2] or '', 'username', fields[3] or '',
+	'disconnectedAt', ARGV[3], 'lastMessageId', ARGV[4])
+return 1
+`)
+
+	// KEYS[1] = leases sorted set, ARGV[1] = proxy ID, ARGV[2] = lease expiry (unix ms)
+	// Returns 0 if the lease doesn't exist or has been renewed
+	deleteLeaseScript = redis.NewScript(`
+local expiresAt = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not expiresAt or tonumber(expiresAt) ~= tonumber(ARGV[2]) then return 0 end
+return redis.call('ZREM', KEYS[1], ARGV[1])
+`)
+
+	// KEYS[1] = proxy set, KEYS[2] = players set, ARGV[1] = key prefix, ARGV[2] = proxy ID,
+	// ARGV[3] = disconnect time (unix ms)
+	// Returns a list of each disconnected player's ID followed by their hash as it was before
+	disconnectProxyPlayersScript = redis.NewScript(redisIndexFunctions + `
+local disconnected = {}
+for _, playerId in ipairs(redis.call('SMEMBERS', KEYS[1])) do
+	local playerKey = prefix .. 'player:' .. playerId
+	if not isAfter(redis.call('HGET', playerKey, 'lastEventAt'), ARGV[3]) then
+		local player = redis.call('HGETALL', playerKey)
+		local fields = redis.call('HMGET', playerKey, 'gameServerId', 'proxyId', 'username', 'fleet')
+		local lastSeenKey = prefix .. 'last-seen:' .. playerId
+		unindexGameServer(playerId, fields[1], fields[4])
+		unindexProxy(playerId, fields[2])
+		redis.call('DEL', playerKey, lastSeenKey)
+		redis.call('SREM', KEYS[2], playerId)
+		redis.call('HSET', lastSeenKey, 'gameServerId', fields[1] or '', 'proxyId', fields[2] or '',
+			'username', fields[3] or '', 'disconnectedAt', ARGV[3])
+		table.insert(disconnected, {playerId, player})
+	end
+end
+return disconnected
+`)
+
+	// KEYS[1] = game server set, KEYS[2] = players set, ARGV[1] = key prefix, ARGV[2] = game server ID,
+	// ARGV[3] = event time (unix ms), ARGV[4] = event time, see redisTime
+	// Returns a list of each cleared player's ID followed by their hash as it was before
+	clearServerPlayersScript = redis.NewScript(redisIndexFunctions + `
+local cleared = {}
+for _, playerId in ipairs(redis.call('SMEMBERS', KEYS[1])) do
+	local playerKey = prefix .. 'player:' .. playerId
+	if not isAfter(redis.call('HGET', playerKey, 'lastEventAt'), ARGV[3]) then
+		local player = redis.call('HGETALL', playerKey)
+		unindexGameServer(playerId, ARGV[2], redis.call('HGET', playerKey, 'fleet'))
+		redis.call('HSET', playerKey, 'gameServerId', '', 'fleet', '', 'deployment', '', 'pod', '',
+			'lastEventAt', ARGV[4], 'lastMessageId', '')
+		redis.call('HINCRBY', playerKey, 'version', 1)
+		table.insert(cleared, {playerId, player})
+	end
+end
+return cleared
+`)
+)
+
+type redisRepository struct {
+	Repository
+	client redis.UniversalClient
+}
+
+// NewRedisRepository creates a Repository backed by Redis.
+// Players are stored as hashes with sets per game server, proxy and fleet so that counts are a single SCARD.
+// All writes are Lua scripts so the sets can never disagree with the player hashes.
+// Every key is in the same slot, so a Redis Cluster stores them all on one node, see redisIndexFunctions.
+func NewRedisRepository(ctx context.Context, cfg *config.RedisConfig) (Repository, error) {
+	var client redis.UniversalClient
+	if cfg.Cluster {
+		if cfg.Database != 0 {
+			return nil, errors.New("redis cluster only has database 0")
+		}
+		client = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    []string{cfg.Address},
+			Password: cfg.Password,
+		})
+	} else {
+		client = redis.NewClient(&redis.Options{
+			Addr:     cfg.Address,
+			Password: cfg.Password,
+			DB:       cfg.Database,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	return &redisRepository{client: client}, nil
+}
+
+func (r *redisRepository) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *redisRepository) Close(_ context.Context) error {
+	return r.client.Close()
+}
+
+func (r *redisRepository) SetPlayerGameServer(ctx context.Context, playerId uuid.UUID, serverId string, at time.Time, messageId string, expectedVersion int64) (*model.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// An invalid ID is still written, it just has no parts so isn't in any fleet
+	gameServer, _ := model.ParseServerID(serverId)
+	return r.runPlayerWrite(ctx, setGameServerScript, playerId, expectedVersion, serverId,
+		gameServer.Fleet, gameServer.Deployment, gameServer.Pod, redisTime(at), messageId)
+}
+
+func (r *redisRepository) SetPlayerProxy(ctx context.Context, playerId uuid.UUID, username string, proxyId string, at time.Time, messageId string, expectedVersion int64) (*model.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return r.runPlayerWrite(ctx, setProxyScript, playerId, expectedVersion, username, proxyId, redisTime(at), messageId)
+}
+
+func (r *redisRepository) GetPlayer(ctx context.Context, playerId uuid.UUID) (*model.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	fields, err := r.client.HGetAll(ctx, redisPlayerKey(playerId)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return redisPlayerFromHash(playerId, fields), nil
+}
+
+func (r *redisRepository) GetPlayers(ctx context.Context, playerIds []uuid.UUID) ([]*model.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return r.getPlayers(ctx, playerIds)
+}
+
+func (r *redisRepository) DeletePlayer(ctx context.Context, playerId uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	deleted, err := deletePlayerScript.Run(ctx, r.client, []string{redisPlayerKey(playerId), redisPlayersKey}, redisKeyPrefix,
+		playerId.String()).Int()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *redisRepository) DisconnectPlayer(ctx context.Context, playerId uuid.UUID, at time.Time, messageId string, expectedVersion int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	keys := []string{redisPlayerKey(playerId), redisLastSeenKeyPrefix + playerId.String(), redisPlayersKey}
+	disconnected, err := disconnectPlayerScript.Run(ctx, r.client, keys, redisKeyPrefix, playerId.String(), at.UnixMilli(),
+		messageId, expectedVersion).Int()
+	if err != nil {
+		return err
+	}
+
+	switch disconnected {
+	case 0:
+		return ErrNotFound
+	case -1:
+		return ErrVersionConflict
+	}
+
+	return nil
+}
+
+func (r *redisRepository) GetPlayerLastSeen(ctx context.Context, playerId uuid.UUID) (*model.LastSeen, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	fields, err := r.client.HGetAll(ctx, redisLastSeenKeyPrefix+playerId.String()).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, ErrNotFound
+	}
+
+	disconnectedAt, err := strconv.ParseInt(fields["disconnectedAt"], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LastSeen{
+		PlayerId:       playerId,
+		Username:       fields["username"],
+		GameServerId:   fields["gameServerId"],
+		ProxyId:        fields["proxyId"],
+		DisconnectedAt: time.UnixMilli(disconnectedAt),
+		LastMessageId:  fields["lastMessageId"],
+	}, nil
+}
+
+func (r *redisRepository) DisconnectProxyPlayers(ctx context.Context, proxyId string, at time.Time) ([]*model.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	testHookPurge()
+	return r.runPlayersWrite(ctx, disconnectProxyPlayersScript, redisProxyKeyPrefix+proxyId, proxyId, at)
+}
+
+func (r *redisRepository) ClearServerPlayers(ctx context.Context, serverId string, at time.Time) ([]*model.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	testHookPurge()
+	return r.runPlayersWrite(ctx, clearServerPlayersScript, redisServerKeyPrefix+serverId, serverId, at)
+}
+
+func (r *redisRepository) RenewProxyLease(ctx context.Context, proxyId string, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return r.client.ZAddGT(ctx, redisLeasesKey, redis.Z{Score: float64(expiresAt.UnixMilli()), Member: proxyId}).Err()
+}
+
+func (r *redisRepository) GetExpiredProxyLeases(ctx context.Context, now time.Time) ([]*model.ProxyLease, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	expired, err := r.client.ZRangeByScoreWithScores(ctx, redisLeasesKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + strconv.FormatInt(now.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	leases := make([]*model.ProxyLease, len(expired))
+	for i, lease := range expired {
+		leases[i] = &model.ProxyLease{ProxyId: lease.Member.(string), ExpiresAt: time.UnixMilli(int64(lease.Score))}
+	}
+	return leases, nil
+}
+
+func (r *redisRepository) DeleteProxyLease(ctx context.Context, lease *model.ProxyLease) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	deleted, err := deleteLeaseScript.Run(ctx, r.client, []string{redisLeasesKey}, lease.ProxyId,
+		lease.ExpiresAt.UnixMilli()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return deleted == 1, nil
+}
+
+func (r *redisRepository) GetServerPlayers(ctx context.Context, serverId string) ([]*model.Player, error) {
+	return r.getIndexPlayers(ctx, redisServerKeyPrefix+serverId)
+}
+
+func (r *redisRepository) GetProxyPlayers(ctx context.Context, proxyId string) ([]*model.Player, error) {
+	return r.getIndexPlayers(ctx, redisProxyKeyPrefix+proxyId)
+}
+
+// getIndexPlayers returns the players in the index set at key
+func (r *redisRepository) getIndexPlayers(ctx context.Context, key string) ([]*model.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	members, err := r.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	playerIds := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		playerId, err := uuid.Parse(member)
+		if err != nil {
+			return nil, err
+		}
+		playerIds = append(playerIds, playerId)
+	}
+
+	return r.getPlayers(ctx, playerIds)
+}
+
+func (r *redisRepository) GetServerPlayerCount(ctx context.Context, serverId model.ServerID) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if serverId.IsProxy() {
+		return r.client.SCard(ctx, redisProxyKeyPrefix+serverId.String()).Result()
+	}
+	return r.client.SCard(ctx, redisServerKeyPrefix+serverId.String()).Result()
+}
+
+func (r *redisRepository) GetFleetPlayerCounts(ctx context.Context, fleets []string) (map[string]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cmds := make([]*redis.IntCmd, len(fleets))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, fleet := range fleets {
+			cmds[i] = pipe.SCard(ctx, redisFleetKeyPrefix+fleet)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(fleets))
+	for i, fleet := range fleets {
+		counts[fleet] = cmds[i].Val()
+	}
+
+	return counts, nil
+}
+
+func (r *redisRepository) GetProxyPlayerCounts(ctx context.Context) (map[string]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Every key is in the same slot, so in a cluster only the node serving it has to be scanned
+	var scanner redis.Cmdable = r.client
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		node, err := cluster.MasterForKey(ctx, redisPlayersKey)
+		if err != nil {
+			return nil, err
+		}
+		scanner = node
+	}
+
+	// Empty sets are deleted by redis, so every proxy set that exists has players
+	var keys []string
+	iter := scanner.Scan(ctx, 0, redisProxyKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	cmds := make([]*redis.IntCmd, len(keys))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.SCard(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(keys))
+	for i, key := range keys {
+		counts[strings.TrimPrefix(key, redisProxyKeyPrefix)] = cmds[i].Val()
+	}
+
+	return counts, nil
+}
+
+func (r *redisRepository) PlayerCount(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return r.client.SCard(ctx, redisPlayersKey).Result()
+}
+
+// getPlayers fetches the hashes of all the given players in a single pipeline.
+// Players that don't exist are skipped.
+func (r *redisRepository) getPlayers(ctx context.Context, playerIds []uuid.UUID) ([]*model.Player, error) {
+	if len(playerIds) == 0 {
+		return nil, nil
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(playerIds))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, playerId := range playerIds {
+			cmds[i] = pipe.HGetAll(ctx, redisPlayerKey(playerId))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	var players []*model.Player
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			continue
+		}
+		players = append(players, redisPlayerFromHash(playerIds[i], fields))
+	}
+
+	return players, nil
+}
+
+// runPlayerWrite runs a script that writes a player and returns its updated hash
+func (r *redisRepository) runPlayerWrite(ctx context.Context, script *redis.Script, playerId uuid.UUID, expectedVersion int64,
+	args ...interface{}) (*model.Player, error) {
+	args = append([]interface{}{redisKeyPrefix, playerId.String(), expectedVersion}, args...)
+
+	res, err := script.Run(ctx, r.client, []string{redisPlayerKey(playerId), redisPlayersKey}, args...).StringSlice()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrVersionConflict
+		}
+		return nil, err
+	}
+
+	fields := make(map[string]string, len(res)/2)
+	for i := 0; i+1 < len(res); i += 2 {
+		fields[res[i]] = res[i+1]
+	}
+
+	return redisPlayerFromHash(playerId, fields), nil
+}
+
+// runPlayersWrite runs a script that writes the players in a server's set and returns each player's ID and previous hash
+func (r *redisRepository) runPlayersWrite(ctx context.Context, script *redis.Script, serverKey string, serverId string,
+	at time.Time) ([]*model.Player, error) {
+	keys := []string{serverKey, redisPlayersKey}
+	res, err := script.Run(ctx, r.client, keys, redisKeyPrefix, serverId, at.UnixMilli(), redisTime(at)).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	players := make([]*model.Player, 0, len(res))
+	for _, entry := range res {
+		entry, ok := entry.([]interface{})
+		if !ok || len(entry) != 2 {
+			return nil, fmt.Errorf("unexpected script result %v", entry)
+		}
+
+		playerId, err := uuid.Parse(fmt.Sprint(entry[0]))
+		if err != nil {
+			return nil, err
+		}
+		hash, _ := entry[1].([]interface{})
+
+		fields := make(map[string]string, len(hash)/2)
+		for i := 0; i+1 < len(hash); i += 2 {
+			fields[fmt.Sprint(hash[i])] = fmt.Sprint(hash[i+1])
+		}
+		players = append(players, redisPlayerFromHash(playerId, fields))
+	}
+
+	return players, nil
+}
+
+func redisPlayerKey(playerId uuid.UUID) string {
+	return redisPlayerKeyPrefix + playerId.String()
+}
+
+func redisPlayerFromHash(playerId uuid.UUID, fields map[string]string) *model.Player {
+	// The version is always written by the scripts, so a parse failure can only mean it's missing
+	version, _ := strconv.ParseInt(fields["version"], 10, 64)
+
+	return &model.Player{
+		Id:           playerId,
+		Username:     fields["username"],
+		GameServerId: fields["gameServerId"],
+		ProxyId:      fields["proxyId"],
+		GameServer: model.ServerID{
+			Fleet:      fields["fleet"],
+			Deployment: fields["deployment"],
+			Pod:        fields["pod"],
+		},
+		LastEventAt:   parseRedisTime(fields["lastEventAt"]),
+		LastMessageId: fields["lastMessageId"],
+		Version:       version,
+	}
+}
+
+// redisTime formats a time as unix milliseconds, or an empty string for the zero time so it reads back as zero
+func redisTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+// parseRedisTime parses a time written by redisTime, returning the zero time if it's empty or invalid
+func parseRedisTime(value string) time.Time {
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(millis)
+}
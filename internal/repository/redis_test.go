@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"player-tracker/internal/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedisRepository_Behaviour(t *testing.T) {
+	testRepositoryBehaviour(t, func(t *testing.T) Repository {
+		return newMiniredisRepository(t)
+	})
+}
+
+// Every key has the hash tag so they are all in the same Redis Cluster slot, which the scripts rely on
+func TestRedisRepository_KeysShareSlot(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	repo, err := NewRedisRepository(ctx, &config.RedisConfig{Address: server.Addr()})
+	assert.NoError(t, err)
+
+	at := time.UnixMilli(1680000000000)
+	playerIds := []uuid.UUID{uuid.New(), uuid.New()}
+	for _, playerId := range playerIds {
+		_, err = repo.SetPlayerProxy(ctx, playerId, "Expectational", "proxy-sdgwsd-235eax", at, "", AnyVersion)
+		assert.NoError(t, err)
+		_, err = repo.SetPlayerGameServer(ctx, playerId, "lobby-z24523-sdhbsd", at, "", AnyVersion)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, repo.DisconnectPlayer(ctx, playerIds[0], at, "", AnyVersion))
+	assert.NoError(t, repo.RenewProxyLease(ctx, "proxy-sdgwsd-235eax", at))
+
+	keys := server.Keys()
+	assert.NotEmpty(t, keys)
+	for _, key := range keys {
+		assert.True(t, strings.HasPrefix(key, "{player-tracker}:"), "key %s isn't in the slot", key)
+	}
+}
+
+func newMiniredisRepository(t *testing.T) Repository {
+	server := miniredis.RunT(t)
+
+	repo, err := NewRedisRepository(context.Background(), &config.RedisConfig{Address: server.Addr()})
+	assert.NoError(t, err)
+
+	return repo
+}
@@ -0,0 +1,634 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"player-tracker/internal/repository/model"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRepositoryBehaviour runs the behavioural tests that every Repository implementation must pass.
+// newRepo must return an empty repository, it is called once per subtest.
+func testRepositoryBehaviour(t *testing.T, newRepo func(t *testing.T) Repository) {
+	ctx := context.Background()
+	playerIds := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	// seed connects the players then sends them to their game servers, the same as the listener would
+	seed := func(t *testing.T, repo Repository, players []model.Player) {
+		for _, p := range players {
+			_, err := repo.SetPlayerProxy(ctx, p.Id, p.Username, p.ProxyId, time.Time{}, "", AnyVersion)
+			assert.NoError(t, err)
+			_, err = repo.SetPlayerGameServer(ctx, p.Id, p.GameServerId, time.Time{}, "", AnyVersion)
+			assert.NoError(t, err)
+		}
+	}
+
+	data := []model.Player{
+		{Id: playerIds[0], Username: "Expectational", GameServerId: "lobby-z24523-sdhbsd", ProxyId: "proxy-sdgwsd-235eax", GameServer: model.ServerID{Fleet: "lobby", Deployment: "z24523", Pod: "sdhbsd"}, Version: 2},
+		{Id: playerIds[1], Username: "Emortal", GameServerId: "lobby-z24523-sdhbsd", ProxyId: "proxy-hsdjrn-2ndjd2", GameServer: model.ServerID{Fleet: "lobby", Deployment: "z24523", Pod: "sdhbsd"}, Version: 2},
+		{Id: playerIds[2], Username: "Zak", GameServerId: "block-sumo-2ndkfs-dfd2x", ProxyId: "proxy-sdgwsd-235eax", GameServer: model.ServerID{Fleet: "block-sumo", Deployment: "2ndkfs", Pod: "dfd2x"}, Version: 2},
+	}
+
+	t.Run("set_game_server_inserts", func(t *testing.T) {
+		repo := newRepo(t)
+
+		want := &model.Player{Id: playerIds[0], GameServerId: "lobby-z24523-sdhbsd", GameServer: model.ServerID{Fleet: "lobby", Deployment: "z24523", Pod: "sdhbsd"}, Version: 1}
+
+		written, err := repo.SetPlayerGameServer(ctx, playerIds[0], "lobby-z24523-sdhbsd", time.Time{}, "", AnyVersion)
+		assert.NoError(t, err)
+		assert.Equal(t, want, written)
+
+		got, err := repo.GetPlayer(ctx, playerIds[0])
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("set_proxy_inserts", func(t *testing.T) {
+		repo := newRepo(t)
+
+		want := &model.Player{Id: playerIds[0], Username: "Expectational", ProxyId: "proxy-sdgwsd-235eax", Version: 1}
+
+		written, err := repo.SetPlayerProxy(ctx, playerIds[0], "Expectational", "proxy-sdgwsd-235eax", time.Time{}, "", AnyVersion)
+		assert.NoError(t, err)
+		assert.Equal(t, want, written)
+
+		got, err := repo.GetPlayer(ctx, playerIds[0])
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("set_writes_last_event", func(t *testing.T) {
+		repo := newRepo(t)
+		connectedAt := time.UnixMilli(1680000000000)
+		switchedAt := connectedAt.Add(time.Second)
+
+		written, err := repo.SetPlayerProxy(ctx, playerIds[0], "Expectational", "proxy-sdgwsd-235eax", connectedAt, "1", AnyVersion)
+		assert.NoError(t, err)
+		assert.True(t, connectedAt.Equal(written.LastEventAt))
+		assert.Equal(t, "1", written.LastMessageId)
+
+		written, err = repo.SetPlayerGameServer(ctx, playerIds[0], "lobby-z24523-sdhbsd", switchedAt, "2", AnyVersion)
+		assert.NoError(t, err)
+		assert.True(t, switchedAt.Equal(written.LastEventAt))
+		assert.Equal(t, "2", written.LastMessageId)
+
+		got, err := repo.GetPlayer(ctx, playerIds[0])
+		assert.NoError(t, err)
+		assert.True(t, switchedAt.Equal(got.LastEventAt))
+		assert.Equal(t, "2", got.LastMessageId)
+	})
+
+	t.Run("set_game_server_moves_player", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, data[:1])
+
+		_, err := repo.SetPlayerGameServer(ctx, playerIds[0], "block-sumo-2ndkfs-dfd2x", time.Time{}, "", AnyVersion)
+		assert.NoError(t, err)
+
+		got, err := repo.GetPlayer(ctx, playerIds[0])
+		assert.NoError(t, err)
+		assert.Equal(t, &model.Player{Id: playerIds[0], Username: "Expectational", GameServerId: "block-sumo-2ndkfs-dfd2x",
+			ProxyId: "proxy-sdgwsd-235eax", GameServer: model.ServerID{Fleet: "block-sumo", Deployment: "2ndkfs", Pod: "dfd2x"}, Version: 3}, got)
+
+		count, err := repo.GetServerPlayerCount(ctx, mustParseServerID(t, "lobby-z24523-sdhbsd"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+
+		counts, err := repo.GetFleetPlayerCounts(ctx, []string{"lobby", "block-sumo"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"lobby": 0, "block-sumo": 1}, counts)
+	})
+
+	t.Run("version_conflict", func(t *testing.T) {
+		repo := newRepo(t)
+
+		// 0 means the player must not exist yet
+		written, err := repo.SetPlayerProxy(ctx, playerIds[0], "Expectational", "proxy-sdgwsd-235eax", time.Time{}, "", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), written.Version)
+
+		_, err = repo.SetPlayerProxy(ctx, playerIds[0], "Expectational", "proxy-hsdjrn-2ndjd2", time.Time{}, "", 0)
+		assert.Equal(t, ErrVersionConflict, err)
+
+		written, err = repo.SetPlayerGameServer(ctx, playerIds[0], "lobby-z24523-sdhbsd", time.Time{}, "", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), written.Version)
+
+		// A write based on version 1 is now stale
+		_, err = repo.SetPlayerGameServer(ctx, playerIds[0], "block-sumo-2ndkfs-dfd2x", time.Time{}, "", 1)
+		assert.Equal(t, ErrVersionConflict, err)
+
+		// A player that doesn't exist can't be at any version but 0
+		_, err = repo.SetPlayerGameServer(ctx, playerIds[1], "block-sumo-2ndkfs-dfd2x", time.Time{}, "", 3)
+		assert.Equal(t, ErrVersionConflict, err)
+
+		got, err := repo.GetPlayer(ctx, playerIds[0])
+		assert.NoError(t, err)
+		assert.Equal(t, &model.Player{Id: playerIds[0], Username: "Expectational", GameServerId: "lobby-z24523-sdhbsd",
+			ProxyId: "proxy-sdgwsd-235eax", GameServer: model.ServerID{Fleet: "lobby", Deployment: "z24523", Pod: "sdhbsd"}, Version: 2}, got)
+
+		_, err = repo.GetPlayer(ctx, playerIds[1])
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	t.Run("concurrent_unconditional_writes", func(t *testing.T) {
+		repo := newRepo(t)
+		const writers = 50
+
+		// Half of the writers change the proxy and half change the server, all at once for the same new player.
+		// None may fail and no increment may be lost.
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				var err error
+				if i%2 == 0 {
+					_, err = repo.SetPlayerProxy(ctx, playerIds[0], "Expectational", fmt.Sprintf("proxy-sdgwsd-%d", i), time.Time{}, "", AnyVersion)
+				} else {
+					_, err = repo.SetPlayerGameServer(ctx, playerIds[0], fmt.Sprintf("lobby-z24523-%d", i), time.Time{}, "", AnyVersion)
+				}
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+
+		got, err := repo.GetPlayer(ctx, playerIds[0])
+		assert.NoError(t, err)
+		assert.Equal(t, int64(writers), got.Version)
+
+		// The indexes must only contain the final location
+		count, err := repo.GetServerPlayerCount(ctx, got.GameServer)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		count, err = repo.GetServerPlayerCount(ctx, mustParseServerID(t, got.ProxyId))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		counts, err := repo.GetFleetPlayerCounts(ctx, []string{"lobby"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"lobby": 1}, counts)
+
+		count, err = repo.PlayerCount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("concurrent_conditional_writes", func(t *testing.T) {
+		repo := newRepo(t)
+		const writers = 20
+
+		// Every writer does a read-modify-write, retrying on conflicts.
+		// If stale writes weren't rejected, some writes would be lost and the version would be lower.
+		var wg sync.WaitGroup
+		var conflicts int64
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				for {
+					var version int64
+					current, err := repo.GetPlayer(ctx, playerIds[0])
+					if err == nil {
+						version = current.Version
+					} else if err != ErrNotFound {
+						assert.NoError(t, err)
+						return
+					}
+
+					_, err = repo.SetPlayerGameServer(ctx, playerIds[0], fmt.Sprintf("lobby-z24523-%d", i), time.Time{}, "", version)
+					if err == ErrVersionConflict {
+						atomic.AddInt64(&conflicts, 1)
+						continue
+					}
+					assert.NoError(t, err)
+					return
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		got, err := repo.GetPlayer(ctx, playerIds[0])
+		assert.NoError(t, err)
+		assert.Equal(t, int64(writers), got.Version)
+		t.Logf("%d version conflicts were retried", conflicts)
+	})
+
+	t.Run("get_player_doesnt_exist", func(t *testing.T) {
+		repo := newRepo(t)
+
+		got, err := repo.GetPlayer(ctx, playerIds[0])
+		assert.Equal(t, ErrNotFound, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("get_players", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, data[:2])
+
+		got, err := repo.GetPlayers(ctx, []uuid.UUID{playerIds[0], playerIds[2]})
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.Player{&data[0]}, got)
+	})
+
+	t.Run("delete_player_doesnt_exist", func(t *testing.T) {
+		repo := newRepo(t)
+
+		assert.Equal(t, ErrNotFound, repo.DeletePlayer(ctx, playerIds[0]))
+	})
+
+	t.Run("delete_player", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, data)
+
+		assert.NoError(t, repo.DeletePlayer(ctx, playerIds[0]))
+
+		_, err := repo.GetPlayer(ctx, playerIds[0])
+		assert.Equal(t, ErrNotFound, err)
+
+		count, err := repo.PlayerCount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		count, err = repo.GetServerPlayerCount(ctx, mustParseServerID(t, "proxy-sdgwsd-235eax"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		counts, err := repo.GetFleetPlayerCounts(ctx, []string{"lobby"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"lobby": 1}, counts)
+	})
+
+	t.Run("disconnect_player_version_conflict", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, data[:1])
+
+		// The player has been written since version 1 was read, so isn't disconnected
+		assert.Equal(t, ErrVersionConflict, repo.DisconnectPlayer(ctx, playerIds[0], time.Now(), "", 1))
+		_, err := repo.GetPlayer(ctx, playerIds[0])
+		assert.NoError(t, err)
+		_, err = repo.GetPlayerLastSeen(ctx, playerIds[0])
+		assert.Equal(t, ErrNotFound, err)
+
+		assert.NoError(t, repo.DisconnectPlayer(ctx, playerIds[0], time.Now(), "", 2))
+		_, err = repo.GetPlayer(ctx, playerIds[0])
+		assert.Equal(t, ErrNotFound, err)
+
+		// An offline player isn't at any version
+		assert.Equal(t, ErrNotFound, repo.DisconnectPlayer(ctx, playerIds[0], time.Now(), "", 2))
+	})
+
+	t.Run("disconnect_player_doesnt_exist", func(t *testing.T) {
+		repo := newRepo(t)
+
+		assert.Equal(t, ErrNotFound, repo.DisconnectPlayer(ctx, playerIds[0], time.Now(), "", AnyVersion))
+
+		_, err := repo.GetPlayerLastSeen(ctx, playerIds[0])
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	t.Run("disconnect_player", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, data)
+
+		disconnectedAt := time.Now().Truncate(time.Millisecond)
+		assert.NoError(t, repo.DisconnectPlayer(ctx, playerIds[0], disconnectedAt, "3", AnyVersion))
+
+		lastSeen, err := repo.GetPlayerLastSeen(ctx, playerIds[0])
+		assert.NoError(t, err)
+		if assert.NotNil(t, lastSeen) {
+			assert.Equal(t, playerIds[0], lastSeen.PlayerId)
+			assert.Equal(t, "Expectational", lastSeen.Username)
+			assert.Equal(t, "lobby-z24523-sdhbsd", lastSeen.GameServerId)
+			assert.Equal(t, "proxy-sdgwsd-235eax", lastSeen.ProxyId)
+			assert.True(t, disconnectedAt.Equal(lastSeen.DisconnectedAt))
+			assert.Equal(t, "3", lastSeen.LastMessageId)
+		}
+
+		// Offline players must not be included in online queries
+		_, err = repo.GetPlayer(ctx, playerIds[0])
+		assert.Equal(t, ErrNotFound, err)
+
+		count, err := repo.PlayerCount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		players, err := repo.GetServerPlayers(ctx, "lobby-z24523-sdhbsd")
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.Player{&data[1]}, players)
+
+		count, err = repo.GetServerPlayerCount(ctx, mustParseServerID(t, "proxy-sdgwsd-235eax"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		// Players that are online again keep their last seen until they next disconnect
+		_, err = repo.SetPlayerProxy(ctx, playerIds[0], "Expectational", "proxy-hsdjrn-2ndjd2", time.Time{}, "", AnyVersion)
+		assert.NoError(t, err)
+		_, err = repo.GetPlayerLastSeen(ctx, playerIds[0])
+		assert.NoError(t, err)
+	})
+
+	t.Run("disconnect_proxy_players", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, data)
+
+		at := time.UnixMilli(1680000000000)
+		// A player with an event after the proxy went is still connected
+		_, err := repo.SetPlayerGameServer(ctx, playerIds[2], "lobby-z24523-sdhbsd", at.Add(time.Second), "", AnyVersion)
+		assert.NoError(t, err)
+
+		disconnected, err := repo.DisconnectProxyPlayers(ctx, "proxy-sdgwsd-235eax", at)
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.Player{&data[0]}, disconnected)
+
+		lastSeen, err := repo.GetPlayerLastSeen(ctx, playerIds[0])
+		assert.NoError(t, err)
+		if assert.NotNil(t, lastSeen) {
+			assert.Equal(t, "lobby-z24523-sdhbsd", lastSeen.GameServerId)
+			assert.Equal(t, "proxy-sdgwsd-235eax", lastSeen.ProxyId)
+			assert.True(t, at.Equal(lastSeen.DisconnectedAt))
+		}
+
+		_, err = repo.GetPlayer(ctx, playerIds[0])
+		assert.Equal(t, ErrNotFound, err)
+		_, err = repo.GetPlayerLastSeen(ctx, playerIds[2])
+		assert.Equal(t, ErrNotFound, err)
+
+		count, err := repo.PlayerCount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		count, err = repo.GetServerPlayerCount(ctx, mustParseServerID(t, "proxy-sdgwsd-235eax"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		disconnected, err = repo.DisconnectProxyPlayers(ctx, "proxy-doesnt-exist", at)
+		assert.NoError(t, err)
+		assert.Empty(t, disconnected)
+	})
+
+	t.Run("clear_server_players", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, data)
+
+		at := time.UnixMilli(1680000000000)
+		cleared, err := repo.ClearServerPlayers(ctx, "lobby-z24523-sdhbsd", at)
+		assert.NoError(t, err)
+		assert.Equal(t, sortPlayers([]*model.Player{&data[0], &data[1]}), sortPlayers(cleared))
+
+		got, err := repo.GetPlayer(ctx, playerIds[0])
+		assert.NoError(t, err)
+		assert.Equal(t, &model.Player{Id: playerIds[0], Username: "Expectational", ProxyId: "proxy-sdgwsd-235eax",
+			LastEventAt: got.LastEventAt, Version: 3}, got)
+		assert.True(t, at.Equal(got.LastEventAt))
+
+		count, err := repo.GetServerPlayerCount(ctx, mustParseServerID(t, "lobby-z24523-sdhbsd"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+
+		counts, err := repo.GetFleetPlayerCounts(ctx, []string{"lobby", "block-sumo"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"lobby": 0, "block-sumo": 1}, counts)
+
+		// Cleared players are still online
+		count, err = repo.PlayerCount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+
+		// A player who has moved on since isn't cleared
+		_, err = repo.SetPlayerGameServer(ctx, playerIds[0], "lobby-z24523-sdhbsd", at.Add(time.Second), "", AnyVersion)
+		assert.NoError(t, err)
+		cleared, err = repo.ClearServerPlayers(ctx, "lobby-z24523-sdhbsd", at)
+		assert.NoError(t, err)
+		assert.Empty(t, cleared)
+	})
+
+	t.Run("purge_racing_write", func(t *testing.T) {
+		at := time.UnixMilli(1680000000000)
+		tests := []struct {
+			name  string
+			purge func(repo Repository) ([]*model.Player, error)
+		}{
+			{name: "disconnect_proxy_players", purge: func(repo Repository) ([]*model.Player, error) {
+				return repo.DisconnectProxyPlayers(ctx, "proxy-sdgwsd-235eax", at)
+			}},
+			{name: "clear_server_players", purge: func(repo Repository) ([]*model.Player, error) {
+				return repo.ClearServerPlayers(ctx, "lobby-z24523-sdhbsd", at)
+			}},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				repo := newRepo(t)
+				seed(t, repo, data)
+
+				// The player has an event after the purge while it is in progress, so must be left alone
+				testHookPurge = func() {
+					_, err := repo.SetPlayerGameServer(ctx, playerIds[0], "lobby-z24523-sdhbsd", at.Add(time.Second), "",
+						AnyVersion)
+					assert.NoError(t, err)
+				}
+				defer func() { testHookPurge = func() {} }()
+
+				purged, err := test.purge(repo)
+				assert.NoError(t, err)
+				for _, player := range purged {
+					assert.NotEqual(t, playerIds[0], player.Id)
+				}
+
+				player, err := repo.GetPlayer(ctx, playerIds[0])
+				if assert.NoError(t, err) {
+					assert.Equal(t, "lobby-z24523-sdhbsd", player.GameServerId)
+					assert.Equal(t, "proxy-sdgwsd-235eax", player.ProxyId)
+				}
+				_, err = repo.GetPlayerLastSeen(ctx, playerIds[0])
+				assert.Equal(t, ErrNotFound, err)
+			})
+		}
+	})
+
+	t.Run("proxy_leases", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.UnixMilli(1680000000000)
+
+		assert.NoError(t, repo.RenewProxyLease(ctx, "proxy-sdgwsd-235eax", now.Add(-time.Second)))
+		assert.NoError(t, repo.RenewProxyLease(ctx, "proxy-hsdjrn-2ndjd2", now.Add(time.Second)))
+		// A late heartbeat doesn't shorten the lease
+		assert.NoError(t, repo.RenewProxyLease(ctx, "proxy-hsdjrn-2ndjd2", now.Add(-time.Minute)))
+
+		expired, err := repo.GetExpiredProxyLeases(ctx, now)
+		assert.NoError(t, err)
+		if assert.Len(t, expired, 1) {
+			assert.Equal(t, "proxy-sdgwsd-235eax", expired[0].ProxyId)
+			assert.True(t, now.Add(-time.Second).Equal(expired[0].ExpiresAt))
+		}
+
+		// A lease renewed since it was read isn't deleted
+		renewed := &model.ProxyLease{ProxyId: "proxy-hsdjrn-2ndjd2", ExpiresAt: now.Add(-time.Minute)}
+		deleted, err := repo.DeleteProxyLease(ctx, renewed)
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+
+		deleted, err = repo.DeleteProxyLease(ctx, expired[0])
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+		deleted, err = repo.DeleteProxyLease(ctx, expired[0])
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+
+		expired, err = repo.GetExpiredProxyLeases(ctx, now.Add(time.Minute))
+		assert.NoError(t, err)
+		if assert.Len(t, expired, 1) {
+			assert.Equal(t, "proxy-hsdjrn-2ndjd2", expired[0].ProxyId)
+			assert.True(t, now.Add(time.Second).Equal(expired[0].ExpiresAt))
+		}
+	})
+
+	t.Run("get_server_players", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, data)
+
+		got, err := repo.GetServerPlayers(ctx, "lobby-z24523-sdhbsd")
+		assert.NoError(t, err)
+		assert.Equal(t, sortPlayers([]*model.Player{&data[0], &data[1]}), sortPlayers(got))
+
+		got, err = repo.GetServerPlayers(ctx, "lobby-doesnt-exist")
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("get_proxy_players", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, data)
+
+		got, err := repo.GetProxyPlayers(ctx, "proxy-sdgwsd-235eax")
+		assert.NoError(t, err)
+		assert.Equal(t, sortPlayers([]*model.Player{&data[0], &data[2]}), sortPlayers(got))
+
+		got, err = repo.GetProxyPlayers(ctx, "proxy-doesnt-exist")
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("get_server_player_count", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, data)
+
+		tests := []struct {
+			targetId string
+			want     int64
+		}{
+			{targetId: "lobby-z24523-sdhbsd", want: 2},
+			{targetId: "block-sumo-2ndkfs-dfd2x", want: 1},
+			{targetId: "lobby-doesnt-exist", want: 0},
+			{targetId: "proxy-sdgwsd-235eax", want: 2},
+			{targetId: "proxy-hsdjrn-2ndjd2", want: 1},
+		}
+
+		for _, test := range tests {
+			got, err := repo.GetServerPlayerCount(ctx, mustParseServerID(t, test.targetId))
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got, test.targetId)
+		}
+	})
+
+	t.Run("get_fleet_player_counts", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, data)
+
+		tests := []struct {
+			name   string
+			fleets []string
+			want   map[string]int64
+		}{
+			{name: "no_fleets", fleets: nil, want: map[string]int64{}},
+			{name: "single_fleet", fleets: []string{"lobby"}, want: map[string]int64{"lobby": 2}},
+			{
+				name:   "multiple_fleets",
+				fleets: []string{"lobby", "block-sumo", "marathon"},
+				want:   map[string]int64{"lobby": 2, "block-sumo": 1, "marathon": 0},
+			},
+			// Fleets are matched exactly, not by prefix
+			{name: "partial_fleet_names", fleets: []string{"lob", "block"}, want: map[string]int64{"lob": 0, "block": 0}},
+		}
+
+		for _, test := range tests {
+			got, err := repo.GetFleetPlayerCounts(ctx, test.fleets)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got, test.name)
+		}
+	})
+
+	t.Run("fleet_counts_follow_moves", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, data)
+
+		_, err := repo.SetPlayerGameServer(ctx, playerIds[2], "lobby-z24523-sdhbsd", time.Time{}, "", AnyVersion)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.DisconnectPlayer(ctx, playerIds[0], time.Now(), "", AnyVersion))
+
+		counts, err := repo.GetFleetPlayerCounts(ctx, []string{"lobby", "block-sumo"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"lobby": 2, "block-sumo": 0}, counts)
+	})
+
+	t.Run("get_proxy_player_counts", func(t *testing.T) {
+		repo := newRepo(t)
+
+		counts, err := repo.GetProxyPlayerCounts(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, counts)
+
+		seed(t, repo, data)
+
+		counts, err = repo.GetProxyPlayerCounts(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"proxy-sdgwsd-235eax": 2, "proxy-hsdjrn-2ndjd2": 1}, counts)
+
+		// A proxy with no players left isn't returned
+		assert.NoError(t, repo.DisconnectPlayer(ctx, playerIds[1], time.Now(), "", AnyVersion))
+
+		counts, err = repo.GetProxyPlayerCounts(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"proxy-sdgwsd-235eax": 2}, counts)
+	})
+
+	t.Run("player_count", func(t *testing.T) {
+		repo := newRepo(t)
+
+		count, err := repo.PlayerCount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+
+		seed(t, repo, data)
+
+		count, err = repo.PlayerCount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+}
+
+func sortPlayers(players []*model.Player) []*model.Player {
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Id.String() < players[j].Id.String()
+	})
+	return players
+}
+
+// mustParseServerID parses a server ID that the test knows is valid
+func mustParseServerID(t *testing.T, id string) model.ServerID {
+	serverId, err := model.ParseServerID(id)
+	if err != nil {
+		t.Fatalf("could not parse server id: %s", err)
+	}
+	return serverId
+}
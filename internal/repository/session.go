@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"player-tracker/internal/config"
+	"player-tracker/internal/repository/model"
+	"time"
+)
+
+// SessionRepository stores the history of player sessions.
+// Unlike Repository, data is kept after the player disconnects.
+type SessionRepository interface {
+	// StartSession opens a new session for the player.
+	// Any session the player still has open is ended at the same time, as a player can only be connected once.
+	// It is a no-op if the player already has a session on the proxy started at that time, so it can be retried.
+	StartSession(ctx context.Context, playerId uuid.UUID, username string, proxyId string, at time.Time) error
+
+	// AddSessionHop records the player joining a game server in their open session.
+	// If the player has no open session (e.g. the connect was missed), one is started.
+	// It is a no-op if the open session already has the hop, so it can be retried.
+	AddSessionHop(ctx context.Context, playerId uuid.UUID, serverId string, at time.Time) error
+
+	// EndSession ends the player's open session. It is a no-op if the player has no open session.
+	EndSession(ctx context.Context, playerId uuid.UUID, at time.Time) error
+
+	// GetPlayerSessions returns up to limit of the player's sessions that started before the given time, newest first.
+	GetPlayerSessions(ctx context.Context, playerId uuid.UUID, before time.Time, limit int64) ([]*model.Session, error)
+
+	// Ping checks that the backend can be reached
+	Ping(ctx context.Context) error
+	// Close disconnects from the backend, the SessionRepository can't be used afterwards
+	Close(ctx context.Context) error
+}
+
+// SessionStore returns the backend sessions are kept in, cfg.Sessions or else the same backend as cfg.Repository.
+// Redis can't store sessions, so they must be chosen explicitly with the redis repository.
+func SessionStore(cfg *config.Config) (string, error) {
+	switch cfg.Sessions {
+	case TypeMongoDB, TypeMemory:
+		return cfg.Sessions, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unknown session store %s", cfg.Sessions)
+	}
+
+	switch cfg.Repository {
+	case TypeMongoDB, "":
+		return TypeMongoDB, nil
+	case TypeMemory:
+		return TypeMemory, nil
+	case TypeRedis:
+		return "", errors.New("sessions must be set to mongodb or memory with the redis repository")
+	default:
+		return "", fmt.Errorf("unknown repository type %s", cfg.Repository)
+	}
+}
+
+// NewSessionRepository creates the SessionRepository for the backend selected by SessionStore.
+// If both are kept in MongoDB, repo's client is shared rather than connecting another.
+func NewSessionRepository(ctx context.Context, cfg *config.Config, repo Repository) (SessionRepository, error) {
+	store, err := SessionStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch store {
+	case TypeMongoDB:
+		if repo, ok := repo.(*mongoRepository); ok {
+			return newMongoSessionRepository(repo.db, false), nil
+		}
+		return NewMongoSessionRepository(ctx, cfg.MongoDB)
+	default:
+		return NewMemorySessionRepository(), nil
+	}
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"player-tracker/internal/config"
+	"player-tracker/internal/repository/model"
+	"testing"
+	"time"
+)
+
+// testSessionRepositoryBehaviour runs the behavioural tests that every SessionRepository implementation must pass.
+// newRepo must return an empty repository, it is called once per subtest.
+func testSessionRepositoryBehaviour(t *testing.T, newRepo func(t *testing.T) SessionRepository) {
+	ctx := context.Background()
+	playerId := uuid.New()
+	proxyId := "proxy-sdgwsd-235eax"
+
+	// Mongo stores times with millisecond precision
+	start := time.Now().UTC().Truncate(time.Millisecond)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	farFuture := at(1000)
+
+	t.Run("no_sessions", func(t *testing.T) {
+		repo := newRepo(t)
+
+		got, err := repo.GetPlayerSessions(ctx, playerId, farFuture, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("full_session", func(t *testing.T) {
+		repo := newRepo(t)
+
+		assert.NoError(t, repo.StartSession(ctx, playerId, "Expectational", proxyId, at(0)))
+		assert.NoError(t, repo.AddSessionHop(ctx, playerId, "lobby-z24523-sdhbsd", at(1)))
+		assert.NoError(t, repo.AddSessionHop(ctx, playerId, "block-sumo-2ndkfs-dfd2x", at(2)))
+		assert.NoError(t, repo.EndSession(ctx, playerId, at(3)))
+
+		got, err := repo.GetPlayerSessions(ctx, playerId, farFuture, 10)
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+
+		session := got[0]
+		assert.Equal(t, playerId, session.PlayerId)
+		assert.Equal(t, "Expectational", session.Username)
+		assert.Equal(t, proxyId, session.ProxyId)
+		assert.Equal(t, at(0), session.ConnectedAt)
+		if assert.NotNil(t, session.DisconnectedAt) {
+			assert.Equal(t, at(3), *session.DisconnectedAt)
+		}
+		assert.Equal(t, []model.ServerHop{
+			{ServerId: "lobby-z24523-sdhbsd", JoinedAt: at(1)},
+			{ServerId: "block-sumo-2ndkfs-dfd2x", JoinedAt: at(2)},
+		}, session.ServerHops)
+	})
+
+	t.Run("start_ends_open_session", func(t *testing.T) {
+		repo := newRepo(t)
+
+		assert.NoError(t, repo.StartSession(ctx, playerId, "Expectational", proxyId, at(0)))
+		assert.NoError(t, repo.StartSession(ctx, playerId, "Expectational", "proxy-hsdjrn-2ndjd2", at(5)))
+
+		got, err := repo.GetPlayerSessions(ctx, playerId, farFuture, 10)
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+
+		assert.Equal(t, "proxy-hsdjrn-2ndjd2", got[0].ProxyId)
+		assert.Nil(t, got[0].DisconnectedAt)
+		if assert.NotNil(t, got[1].DisconnectedAt) {
+			assert.Equal(t, at(5), *got[1].DisconnectedAt)
+		}
+	})
+
+	t.Run("retried", func(t *testing.T) {
+		repo := newRepo(t)
+
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, repo.StartSession(ctx, playerId, "Expectational", proxyId, at(0)))
+			assert.NoError(t, repo.AddSessionHop(ctx, playerId, "lobby-z24523-sdhbsd", at(1)))
+		}
+
+		got, err := repo.GetPlayerSessions(ctx, playerId, farFuture, 10)
+		assert.NoError(t, err)
+		if assert.Len(t, got, 1) {
+			assert.Nil(t, got[0].DisconnectedAt)
+			assert.Equal(t, []model.ServerHop{{ServerId: "lobby-z24523-sdhbsd", JoinedAt: at(1)}}, got[0].ServerHops)
+		}
+	})
+
+	// A connect retried after the player reconnected doesn't end the newer session
+	t.Run("retried_after_reconnect", func(t *testing.T) {
+		repo := newRepo(t)
+
+		assert.NoError(t, repo.StartSession(ctx, playerId, "Expectational", proxyId, at(0)))
+		assert.NoError(t, repo.StartSession(ctx, playerId, "Expectational", "proxy-hsdjrn-2ndjd2", at(5)))
+		assert.NoError(t, repo.StartSession(ctx, playerId, "Expectational", proxyId, at(0)))
+
+		got, err := repo.GetPlayerSessions(ctx, playerId, farFuture, 10)
+		assert.NoError(t, err)
+		if assert.Len(t, got, 2) {
+			assert.Nil(t, got[0].DisconnectedAt)
+			if assert.NotNil(t, got[1].DisconnectedAt) {
+				assert.Equal(t, at(5), *got[1].DisconnectedAt)
+			}
+		}
+	})
+
+	t.Run("hop_without_session", func(t *testing.T) {
+		repo := newRepo(t)
+
+		assert.NoError(t, repo.AddSessionHop(ctx, playerId, "lobby-z24523-sdhbsd", at(1)))
+
+		got, err := repo.GetPlayerSessions(ctx, playerId, farFuture, 10)
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, at(1), got[0].ConnectedAt)
+		assert.Equal(t, []model.ServerHop{{ServerId: "lobby-z24523-sdhbsd", JoinedAt: at(1)}}, got[0].ServerHops)
+	})
+
+	t.Run("end_without_session", func(t *testing.T) {
+		repo := newRepo(t)
+
+		assert.NoError(t, repo.EndSession(ctx, playerId, at(1)))
+	})
+
+	t.Run("before_and_limit", func(t *testing.T) {
+		repo := newRepo(t)
+
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, repo.StartSession(ctx, playerId, "Expectational", proxyId, at(i*10)))
+			assert.NoError(t, repo.EndSession(ctx, playerId, at(i*10+5)))
+		}
+		// Another player's sessions must never be returned
+		assert.NoError(t, repo.StartSession(ctx, uuid.New(), "Emortal", proxyId, at(0)))
+
+		got, err := repo.GetPlayerSessions(ctx, playerId, farFuture, 2)
+		assert.NoError(t, err)
+		if assert.Len(t, got, 2) {
+			assert.Equal(t, at(40), got[0].ConnectedAt)
+			assert.Equal(t, at(30), got[1].ConnectedAt)
+		}
+
+		// Where was the player at minute 22?
+		got, err = repo.GetPlayerSessions(ctx, playerId, at(22), 10)
+		assert.NoError(t, err)
+		if assert.Len(t, got, 3) {
+			assert.Equal(t, at(20), got[0].ConnectedAt)
+			assert.Equal(t, at(0), got[2].ConnectedAt)
+		}
+	})
+}
+
+func TestSessionStore(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		sessions   string
+		want       string
+		wantErr    bool
+	}{
+		{name: "default", want: TypeMongoDB},
+		{name: "mongodb", repository: TypeMongoDB, want: TypeMongoDB},
+		{name: "memory", repository: TypeMemory, want: TypeMemory},
+		{name: "redis_unset", repository: TypeRedis, wantErr: true},
+		{name: "redis_mongodb", repository: TypeRedis, sessions: TypeMongoDB, want: TypeMongoDB},
+		{name: "redis_memory", repository: TypeRedis, sessions: TypeMemory, want: TypeMemory},
+		{name: "mongodb_memory", repository: TypeMongoDB, sessions: TypeMemory, want: TypeMemory},
+		{name: "unknown", repository: TypeMongoDB, sessions: TypeRedis, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := SessionStore(&config.Config{Repository: test.repository, Sessions: test.sessions})
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestMemorySessionRepository_Behaviour(t *testing.T) {
+	testSessionRepositoryBehaviour(t, func(t *testing.T) SessionRepository {
+		return NewMemorySessionRepository()
+	})
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/repository"
+	"player-tracker/internal/repository/model"
+	"time"
+)
+
+const (
+	defaultSessionLimit = 10
+	maxSessionLimit     = 100
+)
+
+type playerHistoryService struct {
+	trackerpb.PlayerHistoryServer
+
+	repo     repository.Repository
+	sessions repository.SessionRepository
+}
+
+func NewPlayerHistoryService(repo repository.Repository, sessions repository.SessionRepository) trackerpb.PlayerHistoryServer {
+	return &playerHistoryService{
+		repo:     repo,
+		sessions: sessions,
+	}
+}
+
+func (s *playerHistoryService) GetPlayerSessions(ctx context.Context, req *trackerpb.GetPlayerSessionsRequest) (*trackerpb.GetPlayerSessionsResponse, error) {
+	pId, err := uuid.Parse(req.PlayerId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid player id")
+	}
+
+	limit := int64(req.Limit)
+	if limit == 0 {
+		limit = defaultSessionLimit
+	} else if limit > maxSessionLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be at most %d", maxSessionLimit)
+	}
+
+	before := time.Now()
+	if req.Before != nil {
+		before = req.Before.AsTime()
+	}
+
+	sessions, err := s.sessions.GetPlayerSessions(ctx, pId, before, limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get player sessions from repository: %v", err)
+	}
+
+	protoSessions := make([]*trackerpb.PlayerSession, len(sessions))
+	for i, session := range sessions {
+		protoSessions[i] = sessionToProto(session)
+	}
+
+	return &trackerpb.GetPlayerSessionsResponse{Sessions: protoSessions}, nil
+}
+
+func (s *playerHistoryService) GetPlayerLastSeen(ctx context.Context, req *trackerpb.GetPlayerLastSeenRequest) (*trackerpb.GetPlayerLastSeenResponse, error) {
+	pId, err := uuid.Parse(req.PlayerId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid player id")
+	}
+
+	p, err := s.repo.GetPlayer(ctx, pId)
+	if err == nil {
+		return &trackerpb.GetPlayerLastSeenResponse{
+			Online:   true,
+			Username: p.Username,
+			ServerId: p.GameServerId,
+			ProxyId:  p.ProxyId,
+		}, nil
+	}
+	if err != repository.ErrNotFound {
+		return nil, status.Errorf(codes.Internal, "failed to get player from repository: %v", err)
+	}
+
+	lastSeen, err := s.repo.GetPlayerLastSeen(ctx, pId)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return &trackerpb.GetPlayerLastSeenResponse{}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get player last seen from repository: %v", err)
+	}
+
+	return &trackerpb.GetPlayerLastSeenResponse{
+		Username: lastSeen.Username,
+		ServerId: lastSeen.GameServerId,
+		ProxyId:  lastSeen.ProxyId,
+		LastSeen: timestamppb.New(lastSeen.DisconnectedAt),
+	}, nil
+}
+
+func sessionToProto(session *model.Session) *trackerpb.PlayerSession {
+	hops := make([]*trackerpb.ServerHop, len(session.ServerHops))
+	for i, hop := range session.ServerHops {
+		hops[i] = &trackerpb.ServerHop{
+			ServerId: hop.ServerId,
+			JoinedAt: timestamppb.New(hop.JoinedAt),
+		}
+	}
+
+	protoSession := &trackerpb.PlayerSession{
+		SessionId:   session.Id.String(),
+		PlayerId:    session.PlayerId.String(),
+		Username:    session.Username,
+		ProxyId:     session.ProxyId,
+		ConnectedAt: timestamppb.New(session.ConnectedAt),
+		ServerHops:  hops,
+	}
+	if session.DisconnectedAt != nil {
+		protoSession.DisconnectedAt = timestamppb.New(*session.DisconnectedAt)
+	}
+
+	return protoSession
+}
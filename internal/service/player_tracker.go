@@ -6,37 +6,24 @@ import (
 	"github.com/emortalmc/proto-specs/gen/go/model/common"
 	pbmodel "github.com/emortalmc/proto-specs/gen/go/model/player_tracker"
 	"github.com/google/uuid"
-	"go.mongodb.org/mongo-driver/mongo"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"log"
+	"player-tracker/internal/fleet"
 	"player-tracker/internal/repository"
-	"strings"
-)
-
-var (
-	serverTypeToFleet = map[common.ServerType]string{
-		common.ServerType_LOBBY:           "lobby",
-		common.ServerType_MARATHON:        "marathon",
-		common.ServerType_BLOCK_SUMO:      "block-sumo",
-		common.ServerType_PARKOURTAG:      "parkourtag",
-		common.ServerType_LAZERTAG:        "lazertag",
-		common.ServerType_HOLEY_MOLEY:     "holey-moley",
-		common.ServerType_MARATHON_RACING: "marathon-racing",
-		common.ServerType_BATTLE:          "battle",
-		common.ServerType_MINESWEEPER:     "minesweeper",
-	}
+	"player-tracker/internal/repository/model"
 )
 
 type playerTrackerService struct {
 	pb.PlayerTrackerServer
 
-	repo repository.Repository
+	repo   repository.Repository
+	fleets *fleet.Registry
 }
 
-func NewPlayerTrackerService(repo repository.Repository) pb.PlayerTrackerServer {
+func NewPlayerTrackerService(repo repository.Repository, fleets *fleet.Registry) pb.PlayerTrackerServer {
 	return &playerTrackerService{
-		repo: repo,
+		repo:   repo,
+		fleets: fleets,
 	}
 }
 
@@ -48,7 +35,7 @@ func (s *playerTrackerService) GetPlayerServer(ctx context.Context, req *pb.GetP
 
 	p, err := s.repo.GetPlayer(ctx, pId)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
+		if err == repository.ErrNotFound {
 			return &pb.GetPlayerServerResponse{Server: nil}, nil
 		}
 		return nil, status.Errorf(codes.Internal, "failed to get player from repository: %v", err)
@@ -104,9 +91,13 @@ func (s *playerTrackerService) GetServerPlayers(ctx context.Context, req *pb.Get
 }
 
 func (s *playerTrackerService) GetServerPlayerCount(ctx context.Context, req *pb.GetServerPlayerCountRequest) (*pb.GetServerPlayerCountResponse, error) {
-	proxy := strings.HasPrefix(req.ServerId, "proxy-")
-
-	count, err := s.repo.GetServerPlayerCount(ctx, req.ServerId, proxy)
+	var count int64
+	serverId, err := model.ParseServerID(req.ServerId)
+	if err == nil {
+		count, err = s.repo.GetServerPlayerCount(ctx, serverId)
+	} else {
+		count, err = s.rawServerPlayerCount(ctx, req.ServerId)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get server player count from repository: %v", err)
 	}
@@ -114,53 +105,98 @@ func (s *playerTrackerService) GetServerPlayerCount(ctx context.Context, req *pb
 	return &pb.GetServerPlayerCountResponse{PlayerCount: uint32(count)}, nil
 }
 
-func (s *playerTrackerService) GetServerTypePlayerCount(ctx context.Context, req *pb.GetServerTypePlayerCountRequest) (*pb.ServerTypePlayerCountResponse, error) {
-	if req.ServerType == common.ServerType_PROXY {
-		count, err := s.repo.PlayerCount(ctx)
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to get player count from repository: %v", err)
-		}
-		return &pb.ServerTypePlayerCountResponse{PlayerCount: uint32(count)}, nil
+// rawServerPlayerCount counts the players on a server whose ID doesn't parse. Players are still written with those IDs,
+// just without a parsed ServerID, so they are found by the raw ID, which may be a game server's or a proxy's.
+func (s *playerTrackerService) rawServerPlayerCount(ctx context.Context, serverId string) (int64, error) {
+	players, err := s.repo.GetServerPlayers(ctx, serverId)
+	if err != nil {
+		return 0, err
 	}
 
-	fleet, ok := serverTypeToFleet[req.ServerType]
-	if !ok {
-		return nil, status.Errorf(codes.InvalidArgument, "unknown server type %v", req.ServerType)
+	proxyPlayers, err := s.repo.GetProxyPlayers(ctx, serverId)
+	if err != nil {
+		return 0, err
 	}
-	count, err := s.repo.GetServerTypePlayerCount(ctx, fleet)
+	return int64(len(players) + len(proxyPlayers)), nil
+}
+
+func (s *playerTrackerService) GetServerTypePlayerCount(ctx context.Context, req *pb.GetServerTypePlayerCountRequest) (*pb.ServerTypePlayerCountResponse, error) {
+	counts, err := s.serverTypePlayerCounts(ctx, []common.ServerType{req.ServerType})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get server type player count from repository: %v", err)
+		return nil, err
 	}
 
-	return &pb.ServerTypePlayerCountResponse{PlayerCount: uint32(count)}, nil
+	return &pb.ServerTypePlayerCountResponse{PlayerCount: uint32(counts[req.ServerType])}, nil
 }
 
 func (s *playerTrackerService) GetServerTypesPlayerCount(ctx context.Context, req *pb.GetServerTypesPlayerCountRequest) (*pb.ServerTypesPlayerCountResponse, error) {
-	counts := make(map[int32]uint32, len(req.ServerTypes))
-	for _, t := range req.ServerTypes {
+	counts, err := s.serverTypePlayerCounts(ctx, req.ServerTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	protoCounts := make(map[int32]uint32, len(counts))
+	for t, count := range counts {
+		protoCounts[int32(t.Number())] = uint32(count)
+	}
+
+	return &pb.ServerTypesPlayerCountResponse{PlayerCounts: protoCounts}, nil
+}
+
+// serverTypePlayerCounts counts the players of all the server types with a single repository query for their fleets.
+// PROXY is the total number of online players, other server types are the sum of all their fleets.
+// The returned error is already a gRPC status.
+func (s *playerTrackerService) serverTypePlayerCounts(ctx context.Context, serverTypes []common.ServerType) (map[common.ServerType]int64, error) {
+	counts := make(map[common.ServerType]int64, len(serverTypes))
+
+	var fleets []string
+	typeFleets := make(map[common.ServerType][]string, len(serverTypes))
+	queued := make(map[string]bool)
+	for _, t := range serverTypes {
+		if _, ok := counts[t]; ok {
+			continue
+		}
+		if _, ok := typeFleets[t]; ok {
+			continue
+		}
+
 		if t == common.ServerType_PROXY {
 			count, err := s.repo.PlayerCount(ctx)
 			if err != nil {
 				return nil, status.Errorf(codes.Internal, "failed to get player count from repository: %v", err)
 			}
-			counts[int32(common.ServerType_PROXY.Number())] = uint32(count)
+			counts[t] = count
 			continue
 		}
-		fleet, ok := serverTypeToFleet[t]
+
+		names, ok := s.fleets.Fleets(t)
 		if !ok {
 			return nil, status.Errorf(codes.InvalidArgument, "unknown server type %v", t)
 		}
 
-		count, err := s.repo.GetServerTypePlayerCount(ctx, fleet)
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to get server type player count from repository: %v", err)
+		typeFleets[t] = names
+		for _, name := range names {
+			if !queued[name] {
+				fleets = append(fleets, name)
+				queued[name] = true
+			}
 		}
+	}
+
+	if len(fleets) == 0 {
+		return counts, nil
+	}
 
-		cardinal := int32(t.Number())
-		log.Printf("cardinal: %v for %v", cardinal, t)
+	fleetCounts, err := s.repo.GetFleetPlayerCounts(ctx, fleets)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get fleet player counts from repository: %v", err)
+	}
 
-		counts[cardinal] = uint32(count)
+	for t, names := range typeFleets {
+		for _, name := range names {
+			counts[t] += fleetCounts[name]
+		}
 	}
 
-	return &pb.ServerTypesPlayerCountResponse{PlayerCounts: counts}, nil
+	return counts, nil
 }
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/listener"
+	"player-tracker/internal/repository/model"
+	"time"
+)
+
+type playerTrackerAdminService struct {
+	trackerpb.PlayerTrackerAdminServer
+
+	purger listener.Purger
+}
+
+func NewPlayerTrackerAdminService(purger listener.Purger) trackerpb.PlayerTrackerAdminServer {
+	return &playerTrackerAdminService{
+		purger: purger,
+	}
+}
+
+func (s *playerTrackerAdminService) PurgeServer(ctx context.Context, req *trackerpb.PurgeServerRequest) (*trackerpb.PurgeServerResponse, error) {
+	at := time.Now()
+
+	var players []*model.Player
+	var err error
+	switch server := req.Server.(type) {
+	case *trackerpb.PurgeServerRequest_ProxyId:
+		if server.ProxyId == "" {
+			return nil, status.Error(codes.InvalidArgument, "proxy id must not be empty")
+		}
+		players, err = s.purger.PurgeProxy(ctx, server.ProxyId, at)
+	case *trackerpb.PurgeServerRequest_GameServerId:
+		if server.GameServerId == "" {
+			return nil, status.Error(codes.InvalidArgument, "game server id must not be empty")
+		}
+		players, err = s.purger.PurgeGameServer(ctx, server.GameServerId, at)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "a proxy id or game server id is required")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to purge server: %v", err)
+	}
+
+	playerIds := make([]string, len(players))
+	for i, player := range players {
+		playerIds[i] = player.Id.String()
+	}
+
+	return &trackerpb.PurgeServerResponse{PlayerIds: playerIds}, nil
+}
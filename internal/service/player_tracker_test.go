@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	pb "github.com/emortalmc/proto-specs/gen/go/grpc/playertracker"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"player-tracker/internal/fleet"
+	"player-tracker/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestPlayerTrackerService_GetServerPlayerCount(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	fleets, err := fleet.NewRegistry(fleet.DefaultMapping)
+	assert.NoError(t, err)
+	s := NewPlayerTrackerService(repo, fleets)
+
+	players := []struct {
+		proxyId  string
+		serverId string
+	}{
+		{proxyId: "proxy-sdgwsd-235eax", serverId: "lobby-z24523-sdhbsd"},
+		{proxyId: "proxy-sdgwsd-235eax", serverId: "Lobby_1"},
+		{proxyId: "Proxy_1", serverId: "Lobby_1"},
+	}
+	for _, p := range players {
+		playerId := uuid.New()
+		_, err := repo.SetPlayerProxy(ctx, playerId, "Expectational", p.proxyId, time.Now(), "", repository.AnyVersion)
+		assert.NoError(t, err)
+		_, err = repo.SetPlayerGameServer(ctx, playerId, p.serverId, time.Now(), "", repository.AnyVersion)
+		assert.NoError(t, err)
+	}
+
+	tests := []struct {
+		serverId string
+		want     uint32
+	}{
+		{serverId: "lobby-z24523-sdhbsd", want: 1},
+		{serverId: "proxy-sdgwsd-235eax", want: 2},
+		// IDs that don't parse are still written, so are counted by the raw ID
+		{serverId: "Lobby_1", want: 2},
+		{serverId: "Proxy_1", want: 1},
+		{serverId: "doesnt-exist", want: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.serverId, func(t *testing.T) {
+			res, err := s.GetServerPlayerCount(ctx, &pb.GetServerPlayerCountRequest{ServerId: test.serverId})
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, res.GetPlayerCount())
+		})
+	}
+}
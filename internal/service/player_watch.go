@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	pb "github.com/emortalmc/proto-specs/gen/go/grpc/playertracker"
+	"github.com/emortalmc/proto-specs/gen/go/model/common"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/fleet"
+	"player-tracker/internal/repository"
+	"player-tracker/internal/watch"
+	"time"
+)
+
+const (
+	maxWatchedPlayers     = 100
+	maxWatchedServers     = 100
+	maxWatchedServerTypes = 100
+
+	defaultCountInterval = time.Second
+)
+
+var eventTypeToUpdateType = map[watch.EventType]trackerpb.PlayerUpdate_UpdateType{
+	watch.EventConnect:    trackerpb.PlayerUpdate_CONNECTED,
+	watch.EventSwitch:     trackerpb.PlayerUpdate_SWITCHED_SERVER,
+	watch.EventDisconnect: trackerpb.PlayerUpdate_DISCONNECTED,
+}
+
+type playerWatchService struct {
+	trackerpb.PlayerWatchServer
+
+	repo repository.Repository
+	hub  *watch.Hub
+
+	// counts is used to count players the same way as the unary count RPCs
+	counts        *playerTrackerService
+	countInterval time.Duration
+}
+
+// NewPlayerWatchService creates the PlayerWatch service.
+// countInterval is the minimum time between updates sent to a WatchPlayerCounts stream.
+func NewPlayerWatchService(repo repository.Repository, fleets *fleet.Registry, hub *watch.Hub,
+	countInterval time.Duration) trackerpb.PlayerWatchServer {
+	if countInterval <= 0 {
+		countInterval = defaultCountInterval
+	}
+
+	return &playerWatchService{
+		repo:          repo,
+		hub:           hub,
+		counts:        &playerTrackerService{repo: repo, fleets: fleets},
+		countInterval: countInterval,
+	}
+}
+
+func (s *playerWatchService) WatchPlayer(req *trackerpb.WatchPlayerRequest, stream trackerpb.PlayerWatch_WatchPlayerServer) error {
+	if len(req.PlayerIds) == 0 {
+		return status.Error(codes.InvalidArgument, "no player ids")
+	}
+	if len(req.PlayerIds) > maxWatchedPlayers {
+		return status.Errorf(codes.InvalidArgument, "at most %d players can be watched", maxWatchedPlayers)
+	}
+
+	pIds := make([]uuid.UUID, len(req.PlayerIds))
+	for i, pId := range req.PlayerIds {
+		parsed, err := uuid.Parse(pId)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "invalid player id")
+		}
+		pIds[i] = parsed
+	}
+
+	// Subscribe before reading the snapshot so no change can be missed between the two
+	sub := s.hub.Subscribe(pIds)
+	defer sub.Close()
+
+	ctx := stream.Context()
+	players, err := s.repo.GetPlayers(ctx, pIds)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get players from repository: %v", err)
+	}
+
+	online := make(map[uuid.UUID]*trackerpb.PlayerUpdate, len(players))
+	for _, p := range players {
+		online[p.Id] = &trackerpb.PlayerUpdate{
+			PlayerId: p.Id.String(),
+			Type:     trackerpb.PlayerUpdate_SNAPSHOT,
+			Online:   true,
+			Username: p.Username,
+			ServerId: p.GameServerId,
+			ProxyId:  p.ProxyId,
+		}
+	}
+
+	for _, pId := range pIds {
+		update, ok := online[pId]
+		if !ok {
+			update = &trackerpb.PlayerUpdate{PlayerId: pId.String(), Type: trackerpb.PlayerUpdate_SNAPSHOT}
+		}
+
+		if err := stream.Send(update); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.Events():
+			if !ok {
+				if sub.Dropped() {
+					return status.Error(codes.ResourceExhausted, "fell too far behind the player updates")
+				}
+				return nil
+			}
+
+			if err := stream.Send(eventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *playerWatchService) WatchPlayerCounts(req *trackerpb.WatchPlayerCountsRequest, stream trackerpb.PlayerWatch_WatchPlayerCountsServer) error {
+	if len(req.ServerIds) == 0 && len(req.ServerTypes) == 0 {
+		return status.Error(codes.InvalidArgument, "no server ids or server types")
+	}
+	if len(req.ServerIds) > maxWatchedServers {
+		return status.Errorf(codes.InvalidArgument, "at most %d servers can be watched", maxWatchedServers)
+	}
+	if len(req.ServerTypes) > maxWatchedServerTypes {
+		return status.Errorf(codes.InvalidArgument, "at most %d server types can be watched", maxWatchedServerTypes)
+	}
+
+	serverTypes := make([]common.ServerType, len(req.ServerTypes))
+	for i, t := range req.ServerTypes {
+		serverTypes[i] = common.ServerType(t)
+	}
+
+	// Any player event may change the counts, so all are watched and the counts re-read when there are any
+	sub := s.hub.SubscribeAll()
+	defer func() {
+		sub.Close()
+	}()
+
+	ticker := time.NewTicker(s.countInterval)
+	defer ticker.Stop()
+
+	ctx := stream.Context()
+	var last *trackerpb.PlayerCountsUpdate
+	changed := true
+	for {
+		if changed {
+			counts, err := s.playerCounts(ctx, req.ServerIds, serverTypes)
+			if err != nil {
+				return err
+			}
+
+			update := changedCounts(last, counts)
+			if last == nil || len(update.ServerPlayerCounts) > 0 || len(update.ServerTypePlayerCounts) > 0 {
+				if err := stream.Send(update); err != nil {
+					return err
+				}
+			}
+			last = counts
+			changed = false
+		}
+
+		pending := false
+		for !changed {
+			select {
+			case <-ctx.Done():
+				return nil
+			case _, ok := <-sub.Events():
+				if !ok {
+					if !sub.Dropped() {
+						// The hub was closed as the server is shutting down
+						return nil
+					}
+
+					// The subscription fell behind, which only means there were changes
+					sub.Close()
+					sub = s.hub.SubscribeAll()
+				}
+				pending = true
+			case <-ticker.C:
+				changed = pending
+			}
+		}
+	}
+}
+
+// playerCounts counts the players on each of the servers and server types.
+// The returned error is already a gRPC status.
+func (s *playerWatchService) playerCounts(ctx context.Context, serverIds []string,
+	serverTypes []common.ServerType) (*trackerpb.PlayerCountsUpdate, error) {
+	counts := &trackerpb.PlayerCountsUpdate{
+		ServerPlayerCounts:     make(map[string]uint32, len(serverIds)),
+		ServerTypePlayerCounts: make(map[int32]uint32, len(serverTypes)),
+	}
+
+	for _, serverId := range serverIds {
+		res, err := s.counts.GetServerPlayerCount(ctx, &pb.GetServerPlayerCountRequest{ServerId: serverId})
+		if err != nil {
+			return nil, err
+		}
+		counts.ServerPlayerCounts[serverId] = res.PlayerCount
+	}
+
+	if len(serverTypes) > 0 {
+		typeCounts, err := s.counts.serverTypePlayerCounts(ctx, serverTypes)
+		if err != nil {
+			return nil, err
+		}
+		for t, count := range typeCounts {
+			counts.ServerTypePlayerCounts[int32(t.Number())] = uint32(count)
+		}
+	}
+
+	return counts, nil
+}
+
+// changedCounts returns the counts that are different to the last ones sent, or all counts if none have been sent
+func changedCounts(last *trackerpb.PlayerCountsUpdate, counts *trackerpb.PlayerCountsUpdate) *trackerpb.PlayerCountsUpdate {
+	if last == nil {
+		return counts
+	}
+
+	update := &trackerpb.PlayerCountsUpdate{
+		ServerPlayerCounts:     make(map[string]uint32),
+		ServerTypePlayerCounts: make(map[int32]uint32),
+	}
+	for serverId, count := range counts.ServerPlayerCounts {
+		if last.ServerPlayerCounts[serverId] != count {
+			update.ServerPlayerCounts[serverId] = count
+		}
+	}
+	for t, count := range counts.ServerTypePlayerCounts {
+		if last.ServerTypePlayerCounts[t] != count {
+			update.ServerTypePlayerCounts[t] = count
+		}
+	}
+
+	return update
+}
+
+func eventToProto(event watch.PlayerEvent) *trackerpb.PlayerUpdate {
+	return &trackerpb.PlayerUpdate{
+		PlayerId: event.PlayerId.String(),
+		Type:     eventTypeToUpdateType[event.Type],
+		Online:   event.Type != watch.EventDisconnect,
+		Username: event.Username,
+		ServerId: event.GameServerId,
+		ProxyId:  event.ProxyId,
+		At:       timestamppb.New(event.At),
+	}
+}
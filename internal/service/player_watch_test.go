@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"github.com/emortalmc/proto-specs/gen/go/model/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"player-tracker/gen/trackerpb"
+	"player-tracker/internal/fleet"
+	"player-tracker/internal/repository"
+	"player-tracker/internal/watch"
+	"testing"
+	"time"
+)
+
+// fakeCountsStream collects the updates sent to a WatchPlayerCounts stream
+type fakeCountsStream struct {
+	grpc.ServerStream
+
+	ctx     context.Context
+	updates chan *trackerpb.PlayerCountsUpdate
+}
+
+func (s *fakeCountsStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeCountsStream) Send(update *trackerpb.PlayerCountsUpdate) error {
+	s.updates <- update
+	return nil
+}
+
+func TestPlayerWatchService_WatchPlayerCounts(t *testing.T) {
+	const interval = 50 * time.Millisecond
+
+	repo := repository.NewMemoryRepository()
+	hub := watch.NewHub()
+	fleets, err := fleet.NewRegistry(fleet.DefaultMapping)
+	assert.NoError(t, err)
+	s := NewPlayerWatchService(repo, fleets, hub, interval)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &fakeCountsStream{ctx: ctx, updates: make(chan *trackerpb.PlayerCountsUpdate, 16)}
+	done := make(chan error)
+	go func() {
+		done <- s.WatchPlayerCounts(&trackerpb.WatchPlayerCountsRequest{
+			ServerIds:   []string{"lobby-z24523-sdhbsd", "block-sumo-2ndkfs-dfd2x"},
+			ServerTypes: []int32{int32(common.ServerType_LOBBY)},
+		}, stream)
+	}()
+
+	// next returns the next update, or nil if none is sent within a few intervals
+	next := func() *trackerpb.PlayerCountsUpdate {
+		select {
+		case update := <-stream.updates:
+			return update
+		case <-time.After(4 * interval):
+			return nil
+		}
+	}
+
+	// The first update has every count, even though they're all 0
+	first := next()
+	assert.NotNil(t, first)
+	assert.Equal(t, map[string]uint32{"lobby-z24523-sdhbsd": 0, "block-sumo-2ndkfs-dfd2x": 0}, first.GetServerPlayerCounts())
+	assert.Equal(t, map[int32]uint32{int32(common.ServerType_LOBBY): 0}, first.GetServerTypePlayerCounts())
+
+	// A burst of joins is sent as a single update, with only the counts that changed.
+	// All the joins are written before their events are published, so the first re-read sees every one of them.
+	playerIds := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for _, playerId := range playerIds {
+		_, err := repo.SetPlayerGameServer(ctx, playerId, "lobby-z24523-sdhbsd", time.Now(), "", repository.AnyVersion)
+		assert.NoError(t, err)
+	}
+	for _, playerId := range playerIds {
+		hub.Publish(watch.PlayerEvent{Type: watch.EventSwitch, PlayerId: playerId, GameServerId: "lobby-z24523-sdhbsd"})
+	}
+
+	update := next()
+	assert.NotNil(t, update)
+	assert.Equal(t, map[string]uint32{"lobby-z24523-sdhbsd": 3}, update.GetServerPlayerCounts())
+	assert.Equal(t, map[int32]uint32{int32(common.ServerType_LOBBY): 3}, update.GetServerTypePlayerCounts())
+	assert.Nil(t, next())
+
+	// An event that doesn't change any of the counts sends nothing
+	_, err = repo.SetPlayerGameServer(ctx, playerIds[0], "marathon-2ndkfs-dfd2x", time.Now(), "", repository.AnyVersion)
+	assert.NoError(t, err)
+	_, err = repo.SetPlayerGameServer(ctx, playerIds[0], "lobby-z24523-sdhbsd", time.Now(), "", repository.AnyVersion)
+	assert.NoError(t, err)
+	hub.Publish(watch.PlayerEvent{Type: watch.EventSwitch, PlayerId: playerIds[0], GameServerId: "lobby-z24523-sdhbsd"})
+	assert.Nil(t, next())
+
+	// Moving between the watched servers changes both of their counts, and the lobby server type's
+	_, err = repo.SetPlayerGameServer(ctx, playerIds[1], "block-sumo-2ndkfs-dfd2x", time.Now(), "", repository.AnyVersion)
+	assert.NoError(t, err)
+	hub.Publish(watch.PlayerEvent{Type: watch.EventSwitch, PlayerId: playerIds[1], GameServerId: "block-sumo-2ndkfs-dfd2x"})
+
+	update = next()
+	assert.NotNil(t, update)
+	assert.Equal(t, map[string]uint32{"lobby-z24523-sdhbsd": 2, "block-sumo-2ndkfs-dfd2x": 1}, update.GetServerPlayerCounts())
+	assert.Equal(t, map[int32]uint32{int32(common.ServerType_LOBBY): 2}, update.GetServerTypePlayerCounts())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestPlayerWatchService_WatchPlayerCounts_InvalidArgument(t *testing.T) {
+	fleets, err := fleet.NewRegistry(fleet.DefaultMapping)
+	assert.NoError(t, err)
+	s := NewPlayerWatchService(repository.NewMemoryRepository(), fleets, watch.NewHub(), time.Second)
+
+	tests := []struct {
+		name string
+		req  *trackerpb.WatchPlayerCountsRequest
+	}{
+		{name: "empty", req: &trackerpb.WatchPlayerCountsRequest{}},
+		{name: "too_many_servers", req: &trackerpb.WatchPlayerCountsRequest{ServerIds: make([]string, maxWatchedServers+1)}},
+		{name: "too_many_server_types", req: &trackerpb.WatchPlayerCountsRequest{
+			ServerTypes: make([]int32, maxWatchedServerTypes+1)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stream := &fakeCountsStream{ctx: context.Background(), updates: make(chan *trackerpb.PlayerCountsUpdate, 1)}
+			err := s.WatchPlayerCounts(test.req, stream)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
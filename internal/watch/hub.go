@@ -0,0 +1,217 @@
+package watch
+
+import (
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// subscriptionBuffer is how many events a subscriber can fall behind by before it is dropped
+const subscriptionBuffer = 64
+
+type EventType int
+
+const (
+	EventConnect EventType = iota
+	EventSwitch
+	EventDisconnect
+)
+
+// PlayerEvent is a change to a player's location that has been applied to the repository.
+type PlayerEvent struct {
+	Type     EventType
+	PlayerId uuid.UUID
+	Username string
+
+	// GameServerId and ProxyId are the player's location after the event, they are empty for EventDisconnect
+	GameServerId string
+	ProxyId      string
+
+	At time.Time
+}
+
+// Hub fans out PlayerEvents to the subscribers of each player.
+// Publishing never blocks, a subscriber that doesn't keep up is dropped instead, see Subscription.Events.
+type Hub struct {
+	lock        sync.RWMutex
+	subscribers map[uuid.UUID]map[*Subscription]struct{}
+	// allSubscribers receive the events of every player
+	allSubscribers map[*Subscription]struct{}
+	// closed is set by Close, after which new subscriptions are closed immediately
+	closed bool
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subscribers:    make(map[uuid.UUID]map[*Subscription]struct{}),
+		allSubscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish sends the event to all subscribers of the player
+func (h *Hub) Publish(event PlayerEvent) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	for sub := range h.subscribers[event.PlayerId] {
+		sub.send(event)
+	}
+	for sub := range h.allSubscribers {
+		sub.send(event)
+	}
+}
+
+// Subscribe returns a Subscription to the events of all the players.
+// The Subscription must be closed when it is no longer needed.
+func (h *Hub) Subscribe(playerIds []uuid.UUID) *Subscription {
+	sub := &Subscription{
+		hub:       h,
+		playerIds: playerIds,
+		events:    make(chan PlayerEvent, subscriptionBuffer),
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed {
+		sub.close()
+		return sub
+	}
+
+	for _, playerId := range playerIds {
+		subs, ok := h.subscribers[playerId]
+		if !ok {
+			subs = make(map[*Subscription]struct{})
+			h.subscribers[playerId] = subs
+		}
+		subs[sub] = struct{}{}
+	}
+
+	return sub
+}
+
+// SubscribeAll returns a Subscription to the events of every player.
+// The Subscription must be closed when it is no longer needed.
+func (h *Hub) SubscribeAll() *Subscription {
+	sub := &Subscription{
+		hub:    h,
+		events: make(chan PlayerEvent, subscriptionBuffer),
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed {
+		sub.close()
+		return sub
+	}
+
+	h.allSubscribers[sub] = struct{}{}
+	return sub
+}
+
+// Close closes every Subscription, so that anything waiting on events stops, e.g. when shutting down.
+// Subscriptions made after Close are already closed.
+func (h *Hub) Close() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.closed = true
+	for sub := range h.allSubscribers {
+		sub.closeLocked()
+	}
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			sub.closeLocked()
+		}
+	}
+	h.subscribers = make(map[uuid.UUID]map[*Subscription]struct{})
+	h.allSubscribers = make(map[*Subscription]struct{})
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	delete(h.allSubscribers, sub)
+
+	for _, playerId := range sub.playerIds {
+		subs := h.subscribers[playerId]
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.subscribers, playerId)
+		}
+	}
+}
+
+type Subscription struct {
+	hub       *Hub
+	playerIds []uuid.UUID
+
+	// lock guards closing events, so send never sends on a closed channel
+	lock    sync.Mutex
+	events  chan PlayerEvent
+	closed  bool
+	dropped bool
+}
+
+// Events returns the channel the subscribed players' events are delivered on, in the order they were published.
+// The channel is closed when the Subscription is closed, or if the subscriber fell too far behind, see Dropped.
+func (s *Subscription) Events() <-chan PlayerEvent {
+	return s.events
+}
+
+// Dropped returns true if the Subscription was closed because the subscriber didn't keep up with its events.
+// Events have been missed, so the subscriber should re-read the players' state before subscribing again.
+func (s *Subscription) Dropped() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.dropped
+}
+
+// Close stops delivering events and closes the Events channel. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.close()
+}
+
+func (s *Subscription) send(event PlayerEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		// The buffer is full, the subscriber is dropped rather than blocking the publisher.
+		// It can't unsubscribe here as the publisher holds the hub's read lock, Close does that.
+		s.dropped = true
+		s.close()
+	}
+}
+
+// closeLocked takes the Subscription's lock and closes it
+func (s *Subscription) closeLocked() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.close()
+}
+
+// close closes the events channel, the caller must hold the lock
+func (s *Subscription) close() {
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	close(s.events)
+}
@@ -0,0 +1,118 @@
+package watch
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestHub_Publish(t *testing.T) {
+	hub := NewHub()
+	playerIds := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	first := hub.Subscribe(playerIds[:2])
+	defer first.Close()
+	second := hub.Subscribe(playerIds[1:2])
+	defer second.Close()
+
+	events := []PlayerEvent{
+		{Type: EventConnect, PlayerId: playerIds[0], ProxyId: "proxy-sdgwsd-235eax", At: time.UnixMilli(1)},
+		{Type: EventSwitch, PlayerId: playerIds[1], GameServerId: "lobby-z24523-sdhbsd", At: time.UnixMilli(2)},
+		{Type: EventDisconnect, PlayerId: playerIds[2], At: time.UnixMilli(3)}, // No subscribers
+	}
+	for _, event := range events {
+		hub.Publish(event)
+	}
+
+	assert.Equal(t, events[0], <-first.Events())
+	assert.Equal(t, events[1], <-first.Events())
+	assert.Equal(t, events[1], <-second.Events())
+	assert.Empty(t, first.Events())
+	assert.Empty(t, second.Events())
+}
+
+func TestHub_SlowSubscriberDropped(t *testing.T) {
+	hub := NewHub()
+	playerId := uuid.New()
+
+	slow := hub.Subscribe([]uuid.UUID{playerId})
+	defer slow.Close()
+
+	// One more event than the buffer can hold, which must not block
+	for i := 0; i <= subscriptionBuffer; i++ {
+		hub.Publish(PlayerEvent{Type: EventSwitch, PlayerId: playerId})
+	}
+
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+	assert.Equal(t, subscriptionBuffer, received)
+	assert.True(t, slow.Dropped())
+
+	// A new subscriber for the same player is unaffected
+	fresh := hub.Subscribe([]uuid.UUID{playerId})
+	defer fresh.Close()
+
+	hub.Publish(PlayerEvent{Type: EventDisconnect, PlayerId: playerId})
+	assert.Equal(t, EventDisconnect, (<-fresh.Events()).Type)
+	assert.False(t, fresh.Dropped())
+}
+
+func TestSubscription_Close(t *testing.T) {
+	hub := NewHub()
+	playerId := uuid.New()
+
+	sub := hub.Subscribe([]uuid.UUID{playerId})
+	sub.Close()
+	sub.Close()
+
+	hub.Publish(PlayerEvent{Type: EventConnect, PlayerId: playerId})
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.False(t, sub.Dropped())
+	assert.Empty(t, hub.subscribers)
+}
+
+func TestHub_SubscribeAll(t *testing.T) {
+	hub := NewHub()
+	playerIds := []uuid.UUID{uuid.New(), uuid.New()}
+
+	all := hub.SubscribeAll()
+	defer all.Close()
+
+	for _, playerId := range playerIds {
+		hub.Publish(PlayerEvent{Type: EventConnect, PlayerId: playerId})
+	}
+
+	assert.Equal(t, playerIds[0], (<-all.Events()).PlayerId)
+	assert.Equal(t, playerIds[1], (<-all.Events()).PlayerId)
+
+	all.Close()
+	assert.Empty(t, hub.allSubscribers)
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub()
+	playerId := uuid.New()
+
+	sub := hub.Subscribe([]uuid.UUID{playerId})
+	all := hub.SubscribeAll()
+	hub.Close()
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	_, ok = <-all.Events()
+	assert.False(t, ok)
+	assert.False(t, sub.Dropped())
+
+	// Closing a subscription after the hub is a no-op
+	sub.Close()
+
+	late := hub.Subscribe([]uuid.UUID{playerId})
+	_, ok = <-late.Events()
+	assert.False(t, ok)
+	assert.Empty(t, hub.subscribers)
+}